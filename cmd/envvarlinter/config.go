@@ -0,0 +1,71 @@
+// Copyright 2019 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// defaultConfigPath is the configuration file read if it exists, unless
+// another one is given.
+const defaultConfigPath = ".envvarlinter.yaml"
+
+type rawConfig struct {
+	// IDs of the rules not applied to the files matching each path glob,
+	// relative to the configuration file, * for all rules.
+	Exclude map[string][]string `json:"exclude"`
+}
+
+type config struct {
+	exclusions *checker.Exclusions
+}
+
+// loadConfig reads the configuration file at path or, if path is empty, at
+// defaultConfigPath if it exists. It returns nil if there is no configuration.
+func loadConfig(path string) (*config, error) {
+	if path == "" {
+		if _, err := os.Stat(defaultConfigPath); err != nil {
+			return nil, nil
+		}
+		path = defaultConfigPath
+	}
+	return readConfig(path)
+}
+
+func readConfig(path string) (*config, error) {
+	var b []byte
+	var err error
+	if b, err = ioutil.ReadFile(path); err != nil {
+		return nil, fmt.Errorf("unable to read configuration file %s: %v", path, err)
+	}
+
+	var rc rawConfig
+	if err = yaml.Unmarshal(b, &rc); err != nil {
+		return nil, fmt.Errorf("unable to parse configuration file %s: %v", path, err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return &config{exclusions: checker.NewExclusions(dir, rc.Exclude)}, nil
+}
@@ -15,9 +15,16 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"testing"
+
+	"istio.io/tools/pkg/checker"
 )
 
 func getAbsPath(path string) string {
@@ -29,6 +36,41 @@ func getAbsPath(path string) string {
 
 func TestNoOSEnvRule(t *testing.T) {
 	rpts, _ := getReport([]string{"testdata/"})
+	envmore := getAbsPath("testdata/envmore.go")
+	expectedRpts := []string{getAbsPath("testdata/envalias.go") +
+		":26:6:os.Getenv is disallowed, please see pkg/env instead (no_os_env)",
+		envmore + ":29:6:os.Environ is disallowed, please see pkg/env instead (no_os_env)",
+		envmore + ":30:6:os.ExpandEnv is disallowed, please see pkg/env instead (no_os_env)",
+		envmore + ":31:9:syscall.Getenv is disallowed, please see pkg/env instead (no_os_env)",
+		envmore + ":33:2:viper.AutomaticEnv is disallowed, please see pkg/env instead (no_viper_env)",
+		envmore + ":34:6:viper.BindEnv is disallowed, please see pkg/env instead (no_viper_env)",
+		envmore + ":36:6:viper.BindEnv is disallowed, please see pkg/env instead (no_viper_env)",
+		envmore + ":41:55:the default of flag \"namespace\" is read from the environment with os.Getenv, " +
+			"which is disallowed (no_env_flag_default)",
+		envmore + ":41:55:os.Getenv is disallowed, please see pkg/env instead (no_os_env)",
+		envmore + ":43:28:the default of flag \"config\" is read from the environment with os.ExpandEnv, " +
+			"which is disallowed (no_env_flag_default)",
+		envmore + ":43:28:os.ExpandEnv is disallowed, please see pkg/env instead (no_os_env)",
+		getAbsPath("testdata/envuse.go") +
+			":20:6:os.Getenv is disallowed, please see pkg/env instead (no_os_env)",
+		getAbsPath("testdata/envuse.go") +
+			":21:9:os.LookupEnv is disallowed, please see pkg/env instead (no_os_env)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestNoOSEnvRuleExclude(t *testing.T) {
+	cfg, err := readConfig("testdata/envvarlinter.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := lint([]string{"testdata/"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpts := report.Items()
 	expectedRpts := []string{getAbsPath("testdata/envuse.go") +
 		":20:6:os.Getenv is disallowed, please see pkg/env instead (no_os_env)",
 		getAbsPath("testdata/envuse.go") +
@@ -38,3 +80,422 @@ func TestNoOSEnvRule(t *testing.T) {
 		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
 	}
 }
+
+func TestInventory(t *testing.T) {
+	vars, err := inventory([]string{"testdata/"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := writeInventory(&b, vars, checker.FormatText); err != nil {
+		t.Fatal(err)
+	}
+
+	envmore := getAbsPath("testdata/envmore.go")
+	expected := "ANDDONTDOTHISEITHER " + getAbsPath("testdata/envuse.go") + ":21:9\n" +
+		"DONTDOIT " + getAbsPath("testdata/envuse.go") + ":20:6\n" +
+		"HOME " + envmore + ":30:6\n" +
+		"HOME " + envmore + ":43:28\n" +
+		"HTTP_PROXY " + envmore + ":31:9\n" +
+		"ISTIO_MESH_ID " + envmore + ":34:6\n" +
+		"KUBECONFIG_NAME " + envmore + ":30:6\n" +
+		"MESH_ID " + envmore + ":34:6\n" +
+		"NAMESPACE " + envmore + ":41:55\n" +
+		"NORTHISWAY " + getAbsPath("testdata/envalias.go") + ":26:6\n"
+	if b.String() != expected {
+		t.Errorf("expected %v; actual %v", expected, b.String())
+	}
+}
+
+func TestInventoryEnvPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envvarlinter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{"a.go": `package p
+
+import "istio.io/pkg/env"
+
+var name = "NOT_CONSTANT"
+
+var (
+	_ = env.RegisterStringVar("PILOT_TRACE_SAMPLING", "", "").Get()
+	_ = env.RegisterBoolVar(name, false, "").Get()
+)
+`})
+
+	vars, err := inventory([]string{dir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := writeInventory(&b, vars, checker.FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	expected := `[
+  {
+    "name": "PILOT_TRACE_SAMPLING",
+    "file": "` + filepath.Join(dir, "a.go") + `",
+    "line": 8,
+    "column": 6
+  }
+]
+`
+	if b.String() != expected {
+		t.Errorf("expected %v; actual %v", expected, b.String())
+	}
+}
+
+func TestNoOSEnvRuleDiff(t *testing.T) {
+	report, err := lint([]string{"testdata/envuse.go"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	unfixed, err := report.Fix(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unfixed.Findings()) != 0 {
+		t.Errorf("expected no unfixed lint errors; actual %v", unfixed.Items())
+	}
+
+	path := getAbsPath("testdata/envuse.go")
+	expected := `--- ` + path + `
++++ ` + path + `
+@@ -14,9 +14,13 @@
+ 
+ package testdata
+ 
+-import "os"
++import "istio.io/pkg/env"
+ 
+ func Envuse() {
+-	_ = os.Getenv("DONTDOIT")
+-	_, _ = os.LookupEnv("ANDDONTDOTHISEITHER")
++	_ = dontdoitEnv.Get()
++	_, _ = anddontdothiseitherEnv.Lookup()
+ }
++
++var dontdoitEnv = env.RegisterStringVar("DONTDOIT", "", "")
++
++var anddontdothiseitherEnv = env.RegisterStringVar("ANDDONTDOTHISEITHER", "", "")
+`
+	if b.String() != expected {
+		t.Errorf("expected %v; actual %v", expected, b.String())
+	}
+}
+
+func TestNoOSEnvRuleFix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envvarlinter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, err := ioutil.ReadFile("testdata/envuse.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "envuse.go")
+	if err := ioutil.WriteFile(path, src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := lint([]string{path}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := report.Fix(nil); err != nil {
+		t.Fatal(err)
+	}
+	if rpts, _ := getReport([]string{path}); len(rpts) != 0 {
+		t.Errorf("expected no lint errors after the fixes; actual %v", rpts)
+	}
+	fixed, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`_ = dontdoitEnv.Get()`,
+		`var dontdoitEnv = env.RegisterStringVar("DONTDOIT", "", "")`,
+	} {
+		if !bytes.Contains(fixed, []byte(expected)) {
+			t.Errorf("expected %s in the fixed file; actual %s", expected, fixed)
+		}
+	}
+}
+
+// writeFiles writes the files with the given contents by path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const getenvSrc = `package p
+
+import "os"
+
+var _ = os.Getenv("A")
+`
+
+func TestCheckSkippedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envvarlinter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		".gitignore":          "ignored*.go\n!ignored_not.go\nbuild/\n",
+		"a.go":                getenvSrc,
+		"ignored.go":          getenvSrc,
+		"ignored_not.go":      getenvSrc,
+		"build/b.go":          getenvSrc,
+		"sub/.gitignore":      "/c.go\n",
+		"sub/c.go":            getenvSrc,
+		"sub/d/c.go":          getenvSrc,
+		"vendor/v/v.go":       getenvSrc,
+		"testdata/t.go":       getenvSrc,
+		"_tools/t.go":         getenvSrc,
+		".hidden/h.go":        getenvSrc,
+		"sub/testdata/t.go":   getenvSrc,
+		"sub/vendor/v/v.go":   getenvSrc,
+		"sub/build/b_test.go": getenvSrc,
+	})
+
+	rpts, err := getReport([]string{dir, filepath.Join(dir, "testdata")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expectedRpts []string
+	for _, path := range []string{"a.go", "ignored_not.go", "sub/d/c.go", "testdata/t.go"} {
+		expectedRpts = append(expectedRpts, filepath.Join(dir, path)+
+			":5:9:os.Getenv is disallowed, please see pkg/env instead (no_os_env)")
+	}
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestCheckChangedSince(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envvarlinter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"a.go":       getenvSrc,
+		"b.go":       getenvSrc,
+		".gitignore": "ignored.go\n",
+	})
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	writeFiles(t, dir, map[string]string{
+		"b.go":       getenvSrc + "\nvar _ = os.Getenv(\"B\")\n",
+		"c.go":       getenvSrc,
+		"ignored.go": getenvSrc,
+	})
+
+	checker.ChangedSince = "HEAD"
+	defer func() { checker.ChangedSince = "" }()
+	rpts, err := getReport([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRpts := []string{
+		filepath.Join(dir, "b.go") + ":5:9:os.Getenv is disallowed, please see pkg/env instead (no_os_env)",
+		filepath.Join(dir, "b.go") + ":7:9:os.Getenv is disallowed, please see pkg/env instead (no_os_env)",
+		filepath.Join(dir, "c.go") + ":5:9:os.Getenv is disallowed, please see pkg/env instead (no_os_env)",
+	}
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envvarlinter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"src/go.mod": "module example.com/src\n",
+		"src/a.go":   getenvSrc,
+	})
+	src := filepath.Join(dir, "src")
+
+	checker.CacheDir = filepath.Join(dir, "cache")
+	defer func() { checker.CacheDir = "" }()
+	expectedRpts := []string{filepath.Join(src, "a.go") +
+		":5:9:os.Getenv is disallowed, please see pkg/env instead (no_os_env)"}
+	if rpts, _ := getReport([]string{src}); !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+
+	// Unchanged files are not checked again, so emptying the cached lint
+	// errors drops them.
+	entries, err := filepath.Glob(filepath.Join(checker.CacheDir, "*", "*"))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected a cache entry; actual %v, %v", entries, err)
+	}
+	if err := ioutil.WriteFile(entries[0], []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if rpts, _ := getReport([]string{src}); len(rpts) != 0 {
+		t.Errorf("expected the cached lint errors; actual %v", rpts)
+	}
+
+	writeFiles(t, dir, map[string]string{"src/a.go": getenvSrc + "\n"})
+	if rpts, _ := getReport([]string{src}); !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestReportFormats(t *testing.T) {
+	report, err := lint([]string{"testdata/envuse.go"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := getAbsPath("testdata/envuse.go")
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			checker.FormatText,
+			path + ":20:6:os.Getenv is disallowed, please see pkg/env instead (no_os_env)\n" +
+				path + ":21:9:os.LookupEnv is disallowed, please see pkg/env instead (no_os_env)\n",
+		},
+		{
+			checker.FormatJSON,
+			`[
+  {
+    "file": "` + path + `",
+    "line": 20,
+    "column": 6,
+    "rule": "no_os_env",
+    "message": "os.Getenv is disallowed, please see pkg/env instead",
+    "severity": "error"
+  },
+  {
+    "file": "` + path + `",
+    "line": 21,
+    "column": 9,
+    "rule": "no_os_env",
+    "message": "os.LookupEnv is disallowed, please see pkg/env instead",
+    "severity": "error"
+  }
+]
+`,
+		},
+		{
+			checker.FormatCheckstyle,
+			`<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="5.0">
+  <file name="` + path + `">
+    <error line="20" column="6" severity="error" message="os.Getenv is disallowed, please see pkg/env instead" source="no_os_env"></error>
+    <error line="21" column="9" severity="error" message="os.LookupEnv is disallowed, please see pkg/env instead" source="no_os_env"></error>
+  </file>
+</checkstyle>
+`,
+		},
+		{
+			checker.FormatGitHub,
+			"::error file=testdata/envuse.go,line=20,col=6,title=no_os_env::os.Getenv is disallowed, please see pkg/env instead\n" +
+				"::error file=testdata/envuse.go,line=21,col=9,title=no_os_env::os.LookupEnv is disallowed, please see pkg/env instead\n",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.format, func(t *testing.T) {
+			t.Parallel()
+
+			var b bytes.Buffer
+			if err := report.Write(&b, test.format, "envvarlinter"); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != test.expected {
+				t.Errorf("expected %v; actual %v", test.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestReportFormatSARIF(t *testing.T) {
+	report, err := lint([]string{"testdata/envuse.go"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := report.Write(&b, checker.FormatSARIF, "envvarlinter"); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(b.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("expected a SARIF 2.1.0 log with a run; actual %s", b.String())
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "envvarlinter" || len(run.Tool.Driver.Rules) != 1 ||
+		run.Tool.Driver.Rules[0].ID != "no_os_env" {
+		t.Errorf("expected the envvarlinter tool with the no_os_env rule; actual %+v", run.Tool)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected 2 results; actual %+v", run.Results)
+	}
+	result := run.Results[1]
+	location := result.Locations[0].PhysicalLocation
+	if result.RuleID != "no_os_env" || result.Level != "error" ||
+		location.ArtifactLocation.URI != "testdata/envuse.go" ||
+		location.Region.StartLine != 21 || location.Region.StartColumn != 9 {
+		t.Errorf("expected a no_os_env error at testdata/envuse.go:21:9; actual %+v", result)
+	}
+}
+
+func TestReportUnknownFormat(t *testing.T) {
+	if err := checker.NewLintReport().Write(&bytes.Buffer{}, "yaml", "envvarlinter"); err == nil {
+		t.Error("expected an error; actual nil")
+	}
+}
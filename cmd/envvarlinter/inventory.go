@@ -0,0 +1,98 @@
+// Copyright 2019 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"istio.io/tools/cmd/envvarlinter/rules"
+	"istio.io/tools/pkg/checker"
+)
+
+// envVar is an environment variable read at a position of a file.
+type envVar struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// inventory returns the environment variables read by the files of args with
+// the configuration cfg, if any, sorted by name and position.
+func inventory(args []string, cfg *config) ([]envVar, error) {
+	matcher := RulesMatcher{config: cfg, inventory: true}
+	// The variables read by the whitelisted files are listed too.
+	whitelist := checker.NewWhitelist(map[string][]string{})
+	report := checker.NewLintReport()
+
+	if err := checker.Check(args, &matcher, whitelist, report); err != nil {
+		return nil, err
+	}
+	id := rules.NewEnvInventory().GetID()
+	var vars []envVar
+	for _, f := range report.Findings() {
+		if f.RuleID == "" {
+			// The file could not be checked.
+			return nil, errors.New(f.Message)
+		}
+		if f.RuleID != id {
+			continue
+		}
+		vars = append(vars, envVar{Name: f.Message, File: f.Pos.Filename, Line: f.Pos.Line, Column: f.Pos.Column})
+	}
+	sort.SliceStable(vars, func(i, j int) bool {
+		a, b := vars[i], vars[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return vars, nil
+}
+
+// writeInventory writes the environment variables vars to w in the format,
+// text or json. The text format is a line name file:line:column for each
+// variable.
+func writeInventory(w io.Writer, vars []envVar, format string) error {
+	switch format {
+	case checker.FormatText:
+		for _, v := range vars {
+			if _, err := fmt.Fprintf(w, "%s %s:%d:%d\n", v.Name, v.File, v.Line, v.Column); err != nil {
+				return err
+			}
+		}
+		return nil
+	case checker.FormatJSON:
+		if vars == nil {
+			vars = []envVar{}
+		}
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(vars)
+	default:
+		return fmt.Errorf("unsupported inventory format %q, expected %s or %s",
+			format, checker.FormatText, checker.FormatJSON)
+	}
+}
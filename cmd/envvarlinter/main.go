@@ -16,38 +16,63 @@ package main
 
 import (
 	"flag"
-	"fmt"
 	"os"
 
 	"istio.io/tools/pkg/checker"
 )
 
 func main() {
-	flag.Parse()
-	exitCode := 0
+	inventoryMode := flag.Bool("inventory", false,
+		"list the environment variables read by the files, with their locations, instead of linting them")
+	checker.Main(checker.Linter{
+		Name:              "envvarlinter",
+		DefaultConfigPath: defaultConfigPath,
+		Lint:              lintConfigFile,
+		Run: func(args []string, configPath string, format string) (bool, error) {
+			if !*inventoryMode {
+				return false, nil
+			}
+			cfg, err := loadConfig(configPath)
+			if err != nil {
+				return true, err
+			}
+			vars, err := inventory(args, cfg)
+			if err != nil {
+				return true, err
+			}
+			return true, writeInventory(os.Stdout, vars, format)
+		},
+	})
+}
 
-	items, err := getReport(flag.Args())
+func getReport(args []string) ([]string, error) {
+	report, err := lint(args, nil)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		exitCode = 2
-	} else {
-		for _, r := range items {
-			fmt.Fprintln(os.Stderr, r)
-			exitCode = 2
-		}
+		return []string{}, err
 	}
+	return report.Items(), nil
+}
 
-	os.Exit(exitCode)
+// lintConfigFile checks the files of args with the configuration file at
+// configPath, or the default one, and returns the report of the lint errors.
+func lintConfigFile(args []string, configPath string) (*checker.Report, error) {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return lint(args, cfg)
 }
 
-func getReport(args []string) ([]string, error) {
-	matcher := RulesMatcher{}
+// lint checks the files of args with the configuration cfg, if any, and
+// returns the report of the lint errors.
+func lint(args []string, cfg *config) (*checker.Report, error) {
+	matcher := RulesMatcher{config: cfg}
 	whitelist := checker.NewWhitelist(Whitelist)
 	report := checker.NewLintReport()
 
 	err := checker.Check(args, &matcher, whitelist, report)
 	if err != nil {
-		return []string{}, err
+		return nil, err
 	}
-	return report.Items(), nil
+	return report, nil
 }
@@ -0,0 +1,55 @@
+// Copyright 2019 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// DefaultRules returns the lint rules applied to the go files other than
+// tests.
+func DefaultRules() []checker.Rule {
+	return []checker.Rule{
+		NewNoOsEnv(),
+		NewNoViperEnv(),
+		NewNoEnvFlagDefault(),
+	}
+}
+
+// ruleDocs documents the analyzer of each rule, by rule ID.
+var ruleDocs = map[string]string{
+	NewNoOsEnv().GetID():          "check that environment variables are read through pkg/env",
+	NewNoViperEnv().GetID():       "check that viper is not bound to environment variables",
+	NewNoEnvFlagDefault().GetID(): "check that the defaults of flags are not read from the environment",
+}
+
+// Analyzers returns the go/analysis analyzers of the rules, which check the
+// go files other than tests.
+func Analyzers() []*analysis.Analyzer {
+	var analyzers []*analysis.Analyzer
+	for _, rule := range DefaultRules() {
+		analyzers = append(analyzers, checker.NewAnalyzer(rule, ruleDocs[rule.GetID()], isNotTest))
+	}
+	return analyzers
+}
+
+// isNotTest returns true if path is not a go test file.
+func isNotTest(path string) bool {
+	return !strings.HasSuffix(path, "_test.go")
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// EnvInventory reports each environment variable read by name, however it is
+// read, to list the environment variables rather than flag errors.
+type EnvInventory struct {
+}
+
+// NewEnvInventory creates and returns an EnvInventory object.
+func NewEnvInventory() *EnvInventory {
+	return &EnvInventory{}
+}
+
+// GetID returns env_inventory.
+func (lr *EnvInventory) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check reports the environment variables read by aNode.
+func (lr *EnvInventory) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped reports the environment variables read by aNode, if it reads the
+// environment with the os, syscall or viper packages, or registers variables
+// of pkg/env. The message of each report is the name of a variable. The
+// variables which names are not constant are not reported.
+func (lr *EnvInventory) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	ce, ok := aNode.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	read, ok := readsEnv(info, ce)
+	if !ok {
+		return
+	}
+	for _, name := range read.names {
+		lrp.AddFinding(checker.Finding{
+			Pos:      fs.Position(ce.Pos()),
+			RuleID:   lr.GetID(),
+			Message:  name,
+			Severity: checker.SeverityWarning,
+		})
+	}
+}
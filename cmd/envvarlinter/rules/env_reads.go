@@ -0,0 +1,130 @@
+// Copyright 2019 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/types"
+	"os"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// viperPkg is the import path of viper.
+const viperPkg = "github.com/spf13/viper"
+
+// envRead is a call reading the environment.
+type envRead struct {
+	// fn is the function called, e.g. os.Getenv.
+	fn string
+	// names are the names of the environment variables read, if they are
+	// known, e.g. not for os.Environ or a name which is not a constant.
+	names []string
+}
+
+// envFunc is a package-level function reading the environment.
+type envFunc struct {
+	pkgPath string
+	name    string
+	// arg is the index of the argument naming the variable read, or -1 if
+	// there is none.
+	arg int
+}
+
+// envFuncs are the functions of the standard library reading the environment.
+var envFuncs = []envFunc{
+	{"os", "Getenv", 0},
+	{"os", "LookupEnv", 0},
+	{"os", "Environ", -1},
+	{"os", "ExpandEnv", -1},
+	{"syscall", "Getenv", 0},
+	{"syscall", "Environ", -1},
+}
+
+// readsEnv returns how ce reads the environment, through the standard library,
+// viper or pkg/env, or false if it does not.
+func readsEnv(info *types.Info, ce *ast.CallExpr) (envRead, bool) {
+	for _, f := range envFuncs {
+		if !checker.MatchPkgFuncCall(info, ce, f.pkgPath, f.name) {
+			continue
+		}
+		read := envRead{fn: f.pkgPath + "." + f.name}
+		if f.arg >= 0 && len(ce.Args) > f.arg {
+			read.names = constantNames(info, ce.Args[f.arg])
+		} else if f.name == "ExpandEnv" && len(ce.Args) == 1 {
+			// The variables of a constant string are known.
+			if s, ok := checker.StringValue(info, ce.Args[0]); ok {
+				os.Expand(s, func(name string) string {
+					read.names = append(read.names, name)
+					return ""
+				})
+			}
+		}
+		return read, true
+	}
+
+	if matchViperCall(info, ce, "AutomaticEnv") {
+		return envRead{fn: "viper.AutomaticEnv"}, true
+	}
+	if matchViperCall(info, ce, "BindEnv") && len(ce.Args) > 0 {
+		// viper.BindEnv(key, names...) binds the key to the variables named
+		// names or, if there are none, to the variable named after the key
+		// with the prefix and key replacer of the viper, which are not known.
+		read := envRead{fn: "viper.BindEnv"}
+		for _, arg := range ce.Args[1:] {
+			read.names = append(read.names, constantNames(info, arg)...)
+		}
+		return read, true
+	}
+
+	if name, ok := matchEnvRegisterCall(info, ce); ok && len(ce.Args) > 0 {
+		return envRead{fn: "env." + name, names: constantNames(info, ce.Args[0])}, true
+	}
+	return envRead{}, false
+}
+
+// constantNames returns the value of the expression e as a list of names if
+// it is a constant string.
+func constantNames(info *types.Info, e ast.Expr) []string {
+	if name, ok := checker.StringValue(info, e); ok {
+		return []string{name}
+	}
+	return nil
+}
+
+// matchViperCall returns true if ce calls the function or the method of a
+// viper.Viper named name. Without type information, only the name is matched.
+func matchViperCall(info *types.Info, ce *ast.CallExpr, name string) bool {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	if f := checker.CalledFunc(info, ce); f != nil {
+		return f.Pkg() != nil && f.Pkg().Path() == viperPkg
+	}
+	return true
+}
+
+// matchEnvRegisterCall returns the name of the function of pkg/env registering
+// an environment variable called by ce, e.g. RegisterStringVar, or false if ce
+// calls none.
+func matchEnvRegisterCall(info *types.Info, ce *ast.CallExpr) (string, bool) {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok || !strings.HasPrefix(sel.Sel.Name, "Register") {
+		return "", false
+	}
+	return sel.Sel.Name, checker.MatchPkgFuncCall(info, ce, envPkg, sel.Sel.Name)
+}
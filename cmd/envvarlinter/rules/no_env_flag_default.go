@@ -0,0 +1,121 @@
+// Copyright 2019 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// flagPkgs are the import paths of the flag packages, pflag being the one of
+// cobra.
+var flagPkgs = map[string]bool{"flag": true, "github.com/spf13/pflag": true}
+
+// flagFuncRegexp matches the names of the usual functions defining flags, by
+// which they are recognised without type information.
+var flagFuncRegexp = regexp.MustCompile(
+	`^(Bool|Duration|Float64|Int|Int64|String|StringArray|StringSlice|StringToString|Uint|Uint64)(Var)?P?$`)
+
+// NoEnvFlagDefault flags an error if the default value of a flag is read from
+// the environment, which makes the help of the command, and the documentation
+// generated from it, depend on the environment.
+type NoEnvFlagDefault struct {
+}
+
+// NewNoEnvFlagDefault creates and returns a NoEnvFlagDefault object.
+func NewNoEnvFlagDefault() *NoEnvFlagDefault {
+	return &NoEnvFlagDefault{}
+}
+
+// GetID returns no_env_flag_default.
+func (lr *NoEnvFlagDefault) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check verifies the defaults of flags are not read from the environment.
+func (lr *NoEnvFlagDefault) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode, if it defines a flag with the flag package or
+// with pflag, e.g. with cmd.Flags().StringVar() in a cobra command, does not
+// read the default value of the flag from the environment. If verification
+// fails lrp creates a new report.
+func (lr *NoEnvFlagDefault) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	ce, ok := aNode.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	name, value, ok := flagDefinition(info, ce)
+	if !ok {
+		return
+	}
+	ast.Inspect(value, func(n ast.Node) bool {
+		if vce, ok := n.(*ast.CallExpr); ok {
+			if read, ok := readsEnv(info, vce); ok {
+				flag := "a flag"
+				if s, ok := checker.StringValue(info, name); ok {
+					flag = fmt.Sprintf("flag %q", s)
+				}
+				lrp.AddItem(fs.Position(vce.Pos()), lr.GetID(), fmt.Sprintf(
+					"the default of %s is read from the environment with %s, which is disallowed", flag, read.fn))
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// flagDefinition returns the arguments of the name and the default value of
+// the flag defined by ce, or false if ce does not define a flag. The functions
+// defining flags have a value argument before the last one, usage.
+func flagDefinition(info *types.Info, ce *ast.CallExpr) (ast.Expr, ast.Expr, bool) {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok || len(ce.Args) < 3 {
+		return nil, nil, false
+	}
+	f := checker.CalledFunc(info, ce)
+	if f == nil {
+		if !flagFuncRegexp.MatchString(sel.Sel.Name) {
+			return nil, nil, false
+		}
+		// The functions named *Var and *VarP take a pointer to the variable
+		// of the flag first.
+		nameArg := 0
+		if strings.HasSuffix(strings.TrimSuffix(sel.Sel.Name, "P"), "Var") {
+			nameArg = 1
+		}
+		return ce.Args[nameArg], ce.Args[len(ce.Args)-2], true
+	}
+
+	sig := f.Type().(*types.Signature)
+	params := sig.Params()
+	if f.Pkg() == nil || !flagPkgs[f.Pkg().Path()] || sig.Variadic() || params.Len() != len(ce.Args) ||
+		params.At(params.Len()-1).Name() != "usage" || params.At(params.Len()-2).Name() != "value" {
+		return nil, nil, false
+	}
+	for i := 0; i < params.Len(); i++ {
+		if params.At(i).Name() == "name" {
+			return ce.Args[i], ce.Args[params.Len()-2], true
+		}
+	}
+	return nil, nil, false
+}
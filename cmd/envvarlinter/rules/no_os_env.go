@@ -17,11 +17,19 @@ package rules
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"istio.io/tools/pkg/checker"
 )
 
-// NoOsEnv flags an error if os.Getenv or os.LookupEnv are used.
+// envPkg is the import path of pkg/env.
+const envPkg = "istio.io/pkg/env"
+
+// NoOsEnv flags an error if the environment is read with the os or syscall
+// packages, e.g. with os.Getenv or os.LookupEnv.
 type NoOsEnv struct {
 }
 
@@ -37,11 +45,110 @@ func (lr *NoOsEnv) GetID() string {
 
 // Check verifies there are no calls to os.Getenv or os.LookupEnv
 func (lr *NoOsEnv) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
-	if ce, ok := aNode.(*ast.CallExpr); ok {
-		if MatchCallExpr(ce, "os", "Getenv") {
-			lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(), "os.Getenv is disallowed, please see pkg/env instead")
-		} else if MatchCallExpr(ce, "os", "LookupEnv") {
-			lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(), "os.LookupEnv is disallowed, please see pkg/env instead")
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode does not call os.Getenv, os.LookupEnv,
+// os.Environ, os.ExpandEnv, syscall.Getenv or syscall.Environ, however the
+// packages are imported. If verification fails lrp creates a new report.
+func (lr *NoOsEnv) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	ce, ok := aNode.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	read, ok := readsEnv(info, ce)
+	if !ok || !strings.HasPrefix(read.fn, "os.") && !strings.HasPrefix(read.fn, "syscall.") {
+		return
+	}
+	msg := read.fn + " is disallowed, please see pkg/env instead"
+	switch read.fn {
+	case "os.Getenv":
+		lr.add(ce, "Get", info, fs, lrp, msg)
+	case "os.LookupEnv":
+		lr.add(ce, "Lookup", info, fs, lrp, msg)
+	default:
+		lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(), msg)
+	}
+}
+
+// add reports the call ce of os.Getenv or os.LookupEnv with msg. If the name of
+// the variable is a literal, the fix declares a package variable of pkg/env
+// for it at the end of the file, and replaces the call with its method get,
+// i.e. Get or Lookup, so that the variable is registered once.
+func (lr *NoOsEnv) add(ce *ast.CallExpr, get string, info *types.Info, fs *token.FileSet, lrp *checker.Report, msg string) {
+	pos := fs.Position(ce.Pos())
+	if len(ce.Args) != 1 {
+		lrp.AddItem(pos, lr.GetID(), msg)
+		return
+	}
+	name, ok := ce.Args[0].(*ast.BasicLit)
+	if !ok || name.Kind != token.STRING {
+		lrp.AddItem(pos, lr.GetID(), msg)
+		return
+	}
+	value, err := strconv.Unquote(name.Value)
+	if err != nil {
+		lrp.AddItem(pos, lr.GetID(), msg)
+		return
+	}
+	v := envVarName(value)
+	if v == "" || declared(info, ce.Pos(), v) {
+		lrp.AddItem(pos, lr.GetID(), msg)
+		return
+	}
+	end := fs.File(ce.Pos()).Size()
+	lrp.AddFix(pos, lr.GetID(), msg, checker.SuggestedFix{
+		Message: "Use pkg/env",
+		Edits: []checker.TextEdit{{
+			Offset:  pos.Offset,
+			End:     fs.Position(ce.End()).Offset,
+			NewText: v + "." + get + "()",
+		}, {
+			Offset:  end,
+			End:     end,
+			NewText: "\nvar " + v + " = env.RegisterStringVar(" + name.Value + `, "", "")` + "\n",
+		}},
+		AddImports: []string{envPkg},
+	})
+}
+
+// envVarName returns the name of the Go variable for the environment variable
+// name, e.g. podNameEnv for POD_NAME, or "" if name has no letters or digits
+// to build it from.
+func envVarName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var v []rune
+	for i, w := range words {
+		r := []rune(strings.ToLower(w))
+		if i > 0 {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		v = append(v, r...)
+	}
+	if len(v) == 0 || unicode.IsDigit(v[0]) {
+		return ""
+	}
+	return string(v) + "Env"
+}
+
+// declared returns true if name is declared in the scope of pos, given the
+// type information info of its package. It is false if info is nil.
+func declared(info *types.Info, pos token.Pos, name string) bool {
+	if info == nil {
+		return false
+	}
+	for _, obj := range info.Defs {
+		if obj == nil || obj.Pkg() == nil {
+			continue
+		}
+		scope := obj.Pkg().Scope().Innermost(pos)
+		if scope == nil {
+			scope = obj.Pkg().Scope()
 		}
+		_, found := scope.LookupParent(name, token.NoPos)
+		return found != nil
 	}
+	return false
 }
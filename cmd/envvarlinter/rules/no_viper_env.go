@@ -0,0 +1,57 @@
+// Copyright 2019 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// NoViperEnv flags an error if viper is bound to the environment, with
+// viper.AutomaticEnv or viper.BindEnv.
+type NoViperEnv struct {
+}
+
+// NewNoViperEnv creates and returns a NoViperEnv object.
+func NewNoViperEnv() *NoViperEnv {
+	return &NoViperEnv{}
+}
+
+// GetID returns no_viper_env.
+func (lr *NoViperEnv) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check verifies there are no calls to viper.AutomaticEnv or viper.BindEnv.
+func (lr *NoViperEnv) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode does not call the functions AutomaticEnv or
+// BindEnv of viper, or the methods of a viper.Viper. If verification fails lrp
+// creates a new report.
+func (lr *NoViperEnv) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	ce, ok := aNode.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	if read, ok := readsEnv(info, ce); ok && strings.HasPrefix(read.fn, "viper.") {
+		lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(), read.fn+" is disallowed, please see pkg/env instead")
+	}
+}
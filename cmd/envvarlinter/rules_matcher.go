@@ -24,6 +24,11 @@ import (
 
 // RulesMatcher filters out test files.
 type RulesMatcher struct {
+	// config, if set, excludes rules from files.
+	config *config
+	// inventory, if true, lists the environment variables read by the files
+	// instead of applying the lint rules.
+	inventory bool
 }
 
 // GetRules checks path absp and decides whether absp is a test file. It returns true and test type
@@ -35,5 +40,12 @@ func (rf *RulesMatcher) GetRules(absp string, info os.FileInfo) []checker.Rule {
 		return []checker.Rule{}
 	}
 
-	return []checker.Rule{rules.NewNoOsEnv()}
+	lintRules := rules.DefaultRules()
+	if rf.inventory {
+		lintRules = []checker.Rule{rules.NewEnvInventory()}
+	}
+	if rf.config == nil {
+		return lintRules
+	}
+	return rf.config.exclusions.Filter(absp, lintRules)
 }
@@ -0,0 +1,44 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testdata
+
+import (
+	"flag"
+	"os"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const proxyEnv = "HTTP_PROXY"
+
+func Envmore() {
+	_ = os.Environ()
+	_ = os.ExpandEnv("$HOME/.kube/${KUBECONFIG_NAME}")
+	_, _ = syscall.Getenv(proxyEnv)
+
+	viper.AutomaticEnv()
+	_ = viper.BindEnv("mesh_id", "MESH_ID", "ISTIO_MESH_ID")
+	v := viper.New()
+	_ = v.BindEnv("revision")
+}
+
+func Envflags(cmd *cobra.Command) {
+	var namespace string
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", os.Getenv("NAMESPACE"), "namespace")
+	cmd.PersistentFlags().Bool("verbose", false, "verbose output")
+	_ = flag.String("config", os.ExpandEnv("$HOME/config"), "configuration file")
+}
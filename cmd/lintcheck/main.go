@@ -0,0 +1,31 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// lintcheck runs the rules of testlinter and envvarlinter as go/analysis
+// analyzers, in a single pass over the packages. It runs the default rules of
+// the linters, without their configuration files, exclusions or whitelists.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/multichecker"
+
+	envvarrules "istio.io/tools/cmd/envvarlinter/rules"
+	testrules "istio.io/tools/cmd/testlinter/rules"
+)
+
+func main() {
+	analyzers := testrules.Analyzers(testrules.DefaultRulesList())
+	analyzers = append(analyzers, envvarrules.Analyzers()...)
+	multichecker.Main(analyzers...)
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+
+	"istio.io/tools/cmd/testlinter/rules"
+	"istio.io/tools/pkg/checker"
+)
+
+// runAnalyzers runs analyzers on the package of the files, and returns their
+// diagnostics formatted like the lint reports.
+func runAnalyzers(analyzers []*analysis.Analyzer, fpaths ...string) ([]string, error) {
+	fs := token.NewFileSet()
+	var files []*ast.File
+	for _, fpath := range fpaths {
+		file, err := parser.ParseFile(fs, getAbsPath(fpath), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	tc := &types.Config{Importer: importer.Default(), Error: func(error) {}}
+	pkg, _ := tc.Check("testdata", fs, files, info)
+
+	var rpts []string
+	for _, analyzer := range analyzers {
+		pass := &analysis.Pass{
+			Analyzer:  analyzer,
+			Fset:      fs,
+			Files:     files,
+			Pkg:       pkg,
+			TypesInfo: info,
+			Report: func(d analysis.Diagnostic) {
+				rpts = append(rpts, fmt.Sprintf("%v:%s (%s)", fs.Position(d.Pos), d.Message, d.Category))
+			},
+		}
+		if _, err := analyzer.Run(pass); err != nil {
+			return nil, err
+		}
+	}
+	return rpts, nil
+}
+
+func TestAnalyzers(t *testing.T) {
+	analyzers := rules.Analyzers(map[TestType][]checker.Rule{
+		UnitTest: {rules.NewNoSleep()},
+		E2eTest:  {rules.NewSkipByIssue(), rules.NewNoSleep()},
+	})
+	if err := analysis.Validate(analyzers); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		fpath        string
+		expectedRpts []string
+	}{
+		{
+			"testdata/e2e/e2e_test.go",
+			[]string{getAbsPath("testdata/e2e/e2e_test.go") +
+				":26:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)"},
+		},
+		{
+			"testdata/alias_test.go",
+			[]string{getAbsPath("testdata/alias_test.go") + ":28:2:time.Sleep() is disallowed. (no_sleep)"},
+		},
+		{
+			"testdata/suppress_test.go",
+			[]string{getAbsPath("testdata/suppress_test.go") + ":27:2:time.Sleep() is disallowed. (no_sleep)",
+				getAbsPath("testdata/suppress_test.go") + ":39:2:time.Sleep() is disallowed. (no_sleep)",
+				getAbsPath("testdata/suppress_test.go") +
+					":38:2:lint:ignore directive needs a reason: //lint:ignore <rule-id> <reason> (lint_ignore)"},
+		},
+		{
+			"testdata/integtest_integ_test.go",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.fpath, func(t *testing.T) {
+			t.Parallel()
+
+			rpts, err := runAnalyzers(analyzers, test.fpath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rpts, test.expectedRpts) {
+				t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, test.expectedRpts)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/tools/cmd/testlinter/rules"
+	"istio.io/tools/pkg/checker"
+)
+
+// defaultConfigPath is the configuration file read if it exists, unless
+// another one is given.
+const defaultConfigPath = ".testlinter.yaml"
+
+type rawConfig struct {
+	// IDs of the rules applied to each type of tests: unit, integration or
+	// e2e. Test types which are not listed keep their default rules, with the
+	// parameters of RuleParams.
+	Rules map[string][]string `json:"rules"`
+
+	// parameters of the rules, by rule ID.
+	RuleParams map[string]json.RawMessage `json:"rule_params"`
+
+	// path globs of the integration and e2e tests, relative to the
+	// configuration file. Other test files are unit tests.
+	TestTypes map[string][]string `json:"test_types"`
+
+	// IDs of the rules not applied to the files matching each path glob,
+	// relative to the configuration file, * for all rules.
+	Exclude map[string][]string `json:"exclude"`
+}
+
+type config struct {
+	// rules applied to each type of tests, if configured.
+	rules map[TestType][]checker.Rule
+
+	// absolute path globs of the integration and e2e tests, nil for the
+	// default classification.
+	testTypes map[TestType][]string
+
+	exclusions *checker.Exclusions
+}
+
+// loadConfig reads the configuration file at path or, if path is empty, at
+// defaultConfigPath if it exists. It returns nil if there is no configuration.
+func loadConfig(path string) (*config, error) {
+	if path == "" {
+		if _, err := os.Stat(defaultConfigPath); err != nil {
+			return nil, nil
+		}
+		path = defaultConfigPath
+	}
+	return readConfig(path)
+}
+
+func readConfig(path string) (*config, error) {
+	var b []byte
+	var err error
+	if b, err = ioutil.ReadFile(path); err != nil {
+		return nil, fmt.Errorf("unable to read configuration file %s: %v", path, err)
+	}
+
+	var rc rawConfig
+	if err = yaml.Unmarshal(b, &rc); err != nil {
+		return nil, fmt.Errorf("unable to parse configuration file %s: %v", path, err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	c := &config{
+		rules:      map[TestType][]checker.Rule{},
+		exclusions: checker.NewExclusions(dir, rc.Exclude),
+	}
+
+	for name, ids := range rc.Rules {
+		testType, err := rules.ParseTestType(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration file %s: %v", path, err)
+		}
+		c.rules[testType] = []checker.Rule{}
+		for _, id := range ids {
+			rule, err := rules.NewRule(id, rc.RuleParams[id])
+			if err != nil {
+				return nil, fmt.Errorf("invalid configuration file %s: %v", path, err)
+			}
+			c.rules[testType] = append(c.rules[testType], rule)
+		}
+	}
+
+	// The default rules of the test types which are not listed are configured
+	// by the parameters too.
+	for _, testType := range []TestType{UnitTest, IntegTest, E2eTest} {
+		if _, ok := c.rules[testType]; ok || len(rc.RuleParams) == 0 {
+			continue
+		}
+		c.rules[testType] = []checker.Rule{}
+		for _, rule := range LintRulesList[testType] {
+			if params, ok := rc.RuleParams[rule.GetID()]; ok {
+				if rule, err = rules.NewRule(rule.GetID(), params); err != nil {
+					return nil, fmt.Errorf("invalid configuration file %s: %v", path, err)
+				}
+			}
+			c.rules[testType] = append(c.rules[testType], rule)
+		}
+	}
+
+	for id, params := range rc.RuleParams {
+		// Rules with parameters which are not enabled are still checked.
+		if _, err := rules.NewRule(id, params); err != nil {
+			return nil, fmt.Errorf("invalid configuration file %s: %v", path, err)
+		}
+	}
+
+	if rc.TestTypes != nil {
+		c.testTypes = map[TestType][]string{}
+	}
+	for name, globs := range rc.TestTypes {
+		testType, err := rules.ParseTestType(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration file %s: %v", path, err)
+		}
+		if testType == UnitTest {
+			return nil, fmt.Errorf("invalid configuration file %s: unit tests are the test files "+
+				"which are not integration or e2e tests", path)
+		}
+		for _, glob := range globs {
+			if !filepath.IsAbs(glob) {
+				glob = filepath.Join(dir, glob)
+			}
+			c.testTypes[testType] = append(c.testTypes[testType], glob)
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"istio.io/tools/cmd/testlinter/rules"
+	"istio.io/tools/pkg/checker"
+)
+
+func TestReadConfig(t *testing.T) {
+	clearLintRulesList()
+	cfg, err := readConfig("testdata/testlinter.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rpts, err := lint([]string{"testdata/"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRpts := []string{
+		getAbsPath("testdata/e2e/e2e_test.go") + ":26:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/e2e/e2e_test.go") + ":37:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/flaky_test.go") + ":44:2:goroutine is disallowed. (no_goroutine)",
+		getAbsPath("testdata/integtest_integ_test.go") + ":26:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/integtest_integ_test.go") + ":37:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/integtest_integ_test.go") +
+			":25:1:Missing either 'if testing.Short() { t.Skip() }' or 'if !testing.Short() {}' (short_skip)",
+		getAbsPath("testdata/integtest_integ_test.go") +
+			":41:1:Missing either 'if testing.Short() { t.Skip() }' or 'if !testing.Short() {}' (short_skip)",
+		getAbsPath("testdata/unit_test.go") + ":75:2:goroutine is disallowed. (no_goroutine)"}
+
+	if !reflect.DeepEqual(rpts.Items(), expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts.Items(), expectedRpts)
+	}
+}
+
+func TestReadConfig_DefaultRuleParams(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewSkipByIssue(), rules.NewNoSleep()}
+
+	dir, err := ioutil.TempDir("", "testlinter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".testlinter.yaml")
+	config := "rule_params: {skip_issue: {issue_patterns: ['https://issues\\.example\\.com/[0-9]+']}}"
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	test := filepath.Join(dir, "a_test.go")
+	src := `package a
+
+import (
+	"testing"
+	"time"
+)
+
+func TestA(t *testing.T) {
+	t.Skip("https://issues.example.com/1")
+}
+
+func TestB(t *testing.T) {
+	time.Sleep(time.Second)
+	t.Skip("https://github.com/istio/istio/issues/1")
+}
+`
+	if err := ioutil.WriteFile(test, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := readConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpts, err := lint([]string{test}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRpts := []string{
+		test + ":14:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		test + ":13:2:time.Sleep() is disallowed. (no_sleep)",
+	}
+	if !reflect.DeepEqual(rpts.Items(), expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts.Items(), expectedRpts)
+	}
+}
+
+func TestReadConfig_Invalid(t *testing.T) {
+	tests := []string{
+		"rules: {unit: [no_such_rule]}",
+		"rules: {smoke: [no_sleep]}",
+		"rule_params: {no_sleep: {duration: 1s}}",
+		"rule_params: {skip_issue: {issue_patterns: ['(']}}",
+		"test_types: {unit: ['**']}",
+		"rules: [no_sleep]",
+	}
+
+	dir, err := ioutil.TempDir("", "testlinter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, test := range tests {
+		path := filepath.Join(dir, fmt.Sprintf("%d.yaml", i))
+		if err := ioutil.WriteFile(path, []byte(test), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readConfig(path); err == nil {
+			t.Errorf("expected an error for %s; actual nil", test)
+		}
+	}
+}
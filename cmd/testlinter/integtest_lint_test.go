@@ -15,6 +15,7 @@
 package main
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
@@ -54,3 +55,45 @@ func TestIntegTestSkipByShortRule(t *testing.T) {
 		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
 	}
 }
+
+func TestIntegTestSkipByShortRuleDiff(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[IntegTest] = []checker.Rule{rules.NewSkipByShort()}
+
+	report, err := lint([]string{"testdata/integration/"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := report.Fix(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	path := getAbsPath("testdata/integration/integtest_test.go")
+	expected := `--- ` + path + `
++++ ` + path + `
+@@ -23,6 +23,9 @@
+ 
+ // nolint: testlinter
+ func TestIntegInvalidSkip(t *testing.T) {
++	if testing.Short() {
++		t.Skip("skipping TestIntegInvalidSkip in short mode.")
++	}
+ 	t.Skip("invalid t.Skip without url to GitHub issue.")
+ 	SetCount()
+ 	if Count(1) != 1 {
+@@ -39,6 +42,9 @@
+ 
+ // nolint: testlinter
+ func TestIntegNoShort(t *testing.T) {
++	if testing.Short() {
++		t.Skip("skipping TestIntegNoShort in short mode.")
++	}
+ 	SetCount()
+ 	if Count(1) != 1 {
+ 		t.Error("expected 1")
+`
+	if b.String() != expected {
+		t.Errorf("expected %v; actual %v", expected, b.String())
+	}
+}
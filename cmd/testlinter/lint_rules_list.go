@@ -16,19 +16,8 @@ package main
 
 import (
 	"istio.io/tools/cmd/testlinter/rules"
-	"istio.io/tools/pkg/checker"
 )
 
 // LintRulesList is a map that maps test type to list of lint rules. Linter applies corresponding
 // list of lint rules to each type of tests.
-var LintRulesList = map[TestType][]checker.Rule{
-	UnitTest: { // list of rules which should apply to unit test file
-		rules.NewSkipByIssue(),
-	},
-	IntegTest: { // list of rules which should apply to integration test file
-		rules.NewSkipByIssue(),
-	},
-	E2eTest: { // list of rules which should apply to e2e test file
-		rules.NewSkipByIssue(),
-	},
-}
+var LintRulesList = rules.DefaultRulesList()
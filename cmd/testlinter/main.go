@@ -15,38 +15,45 @@
 package main
 
 import (
-	"flag"
-	"fmt"
-	"os"
-
 	"istio.io/tools/pkg/checker"
 )
 
 func main() {
-	flag.Parse()
-	exitCode := 0
+	checker.Main(checker.Linter{
+		Name:              "testlinter",
+		DefaultConfigPath: defaultConfigPath,
+		Lint:              lintConfigFile,
+	})
+}
 
-	items, err := getReport(flag.Args())
+func getReport(args []string) ([]string, error) {
+	report, err := lint(args, nil)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		exitCode = 2
-	} else {
-		for _, r := range items {
-			fmt.Fprintln(os.Stderr, r)
-			exitCode = 2
-		}
+		return []string{}, err
 	}
-	os.Exit(exitCode)
+	return report.Items(), nil
 }
 
-func getReport(args []string) ([]string, error) {
-	matcher := RulesMatcher{}
+// lintConfigFile checks the files of args with the configuration file at
+// configPath, or the default one, and returns the report of the lint errors.
+func lintConfigFile(args []string, configPath string) (*checker.Report, error) {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return lint(args, cfg)
+}
+
+// lint checks the files of args with the configuration cfg, if any, and
+// returns the report of the lint errors.
+func lint(args []string, cfg *config) (*checker.Report, error) {
+	matcher := RulesMatcher{config: cfg}
 	whitelist := checker.NewWhitelist(Whitelist)
 	report := checker.NewLintReport()
 
 	err := checker.Check(args, &matcher, whitelist, report)
 	if err != nil {
-		return []string{}, err
+		return nil, err
 	}
-	return report.Items(), nil
+	return report, nil
 }
@@ -0,0 +1,85 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"golang.org/x/tools/go/analysis"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// DefaultRulesList returns the lint rules applied to each type of tests by
+// default.
+func DefaultRulesList() map[TestType][]checker.Rule {
+	return map[TestType][]checker.Rule{
+		UnitTest: { // list of rules which should apply to unit test file
+			NewSkipByIssue(),
+			NewParallelSetenv(),
+			NewFatalGoroutine(),
+			NewNoNetwork(),
+			NewRandSeed(),
+			NewTempDirError(),
+		},
+		IntegTest: { // list of rules which should apply to integration test file
+			NewSkipByIssue(),
+			NewParallelSetenv(),
+			NewFatalGoroutine(),
+			NewRandSeed(),
+			NewTempDirError(),
+		},
+		E2eTest: { // list of rules which should apply to e2e test file
+			NewSkipByIssue(),
+			NewParallelSetenv(),
+			NewFatalGoroutine(),
+			NewRandSeed(),
+			NewTempDirError(),
+		},
+	}
+}
+
+// ruleDocs documents the analyzer of each rule, by rule ID.
+var ruleDocs = map[string]string{
+	NewSkipByIssue().GetID():    "check that skipped tests refer to a GitHub issue",
+	NewSkipByShort().GetID():    "check that long running tests are skipped in short mode",
+	NewNoShort().GetID():        "check that tests do not call testing.Short",
+	NewNoSleep().GetID():        "check that tests do not call time.Sleep",
+	NewNoGoroutine().GetID():    "check that tests do not start goroutines",
+	NewParallelSetenv().GetID(): "check that parallel tests do not change the environment",
+	NewFatalGoroutine().GetID(): "check that t.Fatal is not called from goroutines other than the test's",
+	NewNoNetwork().GetID():      "check that tests do not connect to non-loopback hosts",
+	NewRandSeed().GetID():       "check that table tests seed math/rand with a fixed seed",
+	NewTempDirError().GetID():   "check that the errors of ioutil.TempDir and the like are not ignored",
+}
+
+// Analyzers returns a go/analysis analyzer for each rule of rulesList, which
+// checks the test files of the types the rule applies to.
+func Analyzers(rulesList map[TestType][]checker.Rule) []*analysis.Analyzer {
+	var analyzers []*analysis.Analyzer
+	testTypes := map[string]map[TestType]bool{}
+	for _, testType := range []TestType{UnitTest, IntegTest, E2eTest} {
+		for _, rule := range rulesList[testType] {
+			id := rule.GetID()
+			if testTypes[id] == nil {
+				testTypes[id] = map[TestType]bool{}
+				analyzers = append(analyzers, checker.NewAnalyzer(rule, ruleDocs[id], func(path string) bool {
+					testType, ok := TestTypeOf(path)
+					return ok && testTypes[id][testType]
+				}))
+			}
+			testTypes[id][testType] = true
+		}
+	}
+	return analyzers
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// stopMethods are the methods of testing.TB which stop the test by exiting
+// the goroutine they are called from.
+var stopMethods = []string{"Fatal", "Fatalf", "FailNow", "Skip", "Skipf", "SkipNow"}
+
+// FatalGoroutine requires that t.Fatal() is not called from a goroutine other
+// than the test's, where it does not stop the test.
+type FatalGoroutine struct{}
+
+// NewFatalGoroutine creates and returns a FatalGoroutine object.
+func NewFatalGoroutine() *FatalGoroutine {
+	return &FatalGoroutine{}
+}
+
+// GetID returns fatal_goroutine.
+func (lr *FatalGoroutine) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check verifies if aNode is not a goroutine calling t.Fatal(). If
+// verification fails lrp creates a new report.
+func (lr *FatalGoroutine) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode is not a goroutine calling t.Fatal(), t.FailNow()
+// or t.Skip(), except in the subtests it runs, or in the goroutines it starts,
+// which are checked on their own. If verification fails lrp creates a new
+// report.
+func (lr *FatalGoroutine) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	gs, ok := aNode.(*ast.GoStmt)
+	if !ok {
+		return
+	}
+	ast.Inspect(gs.Call, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			return false
+		case *ast.CallExpr:
+			for _, name := range stopMethods {
+				if matchTBCall(info, n, name) {
+					lrp.AddItem(fs.Position(n.Pos()), lr.GetID(),
+						"t."+name+"() from a goroutine other than the test's is disallowed.")
+				}
+			}
+			// Subtests run in goroutines of their own, which they stop.
+			if subtest(info, n) != nil {
+				return false
+			}
+		}
+		return true
+	})
+}
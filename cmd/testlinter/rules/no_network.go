@@ -0,0 +1,122 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// networkCall is a function connecting to a host, which is given by the
+// argument at index arg.
+type networkCall struct {
+	pkgPath string
+	name    string
+	arg     int
+	// url is true if the argument is a URL, and false if it is a host:port
+	// address.
+	url bool
+}
+
+// networkCalls are the functions connecting to a host checked by NoNetwork.
+var networkCalls = []networkCall{
+	{"net/http", "Get", 0, true},
+	{"net/http", "Head", 0, true},
+	{"net/http", "Post", 0, true},
+	{"net/http", "PostForm", 0, true},
+	{"net", "Dial", 1, false},
+	{"net", "DialTimeout", 1, false},
+}
+
+// NoNetwork requires that http.Get() and net.Dial() do not connect to
+// non-loopback hosts, which are not available to every test environment.
+type NoNetwork struct{}
+
+// NewNoNetwork creates and returns a NoNetwork object.
+func NewNoNetwork() *NoNetwork {
+	return &NoNetwork{}
+}
+
+// GetID returns no_network.
+func (lr *NoNetwork) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check verifies if aNode does not connect to a non-loopback host. If
+// verification fails lrp creates a new report.
+func (lr *NoNetwork) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode is not a call of http.Get(), http.Head(),
+// http.Post(), http.PostForm(), net.Dial() or net.DialTimeout() with a constant
+// URL or address of a non-loopback host. If verification fails lrp creates a
+// new report.
+func (lr *NoNetwork) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	ce, ok := aNode.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	for _, call := range networkCalls {
+		if len(ce.Args) <= call.arg || !checker.MatchPkgFuncCall(info, ce, call.pkgPath, call.name) {
+			continue
+		}
+		address, ok := checker.StringValue(info, ce.Args[call.arg])
+		if !ok {
+			return
+		}
+		host := hostOf(address, call.url)
+		if !isLoopback(host) {
+			lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(),
+				path.Base(call.pkgPath)+"."+call.name+
+					"() to the non-loopback host "+host+" is disallowed.")
+		}
+		return
+	}
+}
+
+// hostOf returns the host of the URL, or of the host:port address if isURL is
+// false.
+func hostOf(address string, isURL bool) string {
+	if isURL {
+		u, err := url.Parse(address)
+		if err != nil {
+			return address
+		}
+		return u.Hostname()
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+// isLoopback returns true if host is a loopback host, or empty, i.e. the local
+// host.
+func isLoopback(host string) bool {
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
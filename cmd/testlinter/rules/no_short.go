@@ -17,6 +17,7 @@ package rules
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
 
 	"istio.io/tools/pkg/checker"
 )
@@ -36,8 +37,14 @@ func (lr *NoShort) GetID() string {
 
 // Check verifies if aNode is not testing.Short(). If verification lrp creates new report.
 func (lr *NoShort) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode does not call testing.Short, however the
+// testing package is imported. If verification fails lrp creates a new report.
+func (lr *NoShort) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
 	if ce, ok := aNode.(*ast.CallExpr); ok {
-		if MatchCallExpr(ce, "testing", "Short") {
+		if checker.MatchPkgFuncCall(info, ce, "testing", "Short") {
 			lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(), "testing.Short() is disallowed.")
 		}
 	}
@@ -17,6 +17,7 @@ package rules
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
 
 	"istio.io/tools/pkg/checker"
 )
@@ -36,8 +37,14 @@ func (lr *NoSleep) GetID() string {
 
 // Check verifies if aNode is not time.Sleep. If verification fails lrp creates a new report.
 func (lr *NoSleep) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode does not call time.Sleep, however the time
+// package is imported. If verification fails lrp creates a new report.
+func (lr *NoSleep) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
 	if ce, ok := aNode.(*ast.CallExpr); ok {
-		if MatchCallExpr(ce, "time", "Sleep") {
+		if checker.MatchPkgFuncCall(info, ce, "time", "Sleep") {
 			lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(), "time.Sleep() is disallowed.")
 		}
 	}
@@ -0,0 +1,79 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// envSetters are the functions of the os package which change the environment
+// of the process, shared by the tests run in parallel.
+var envSetters = []string{"Setenv", "Unsetenv", "Clearenv"}
+
+// ParallelSetenv requires that parallel tests do not change the environment
+// with os.Setenv(), which races with the other parallel tests.
+type ParallelSetenv struct{}
+
+// NewParallelSetenv creates and returns a ParallelSetenv object.
+func NewParallelSetenv() *ParallelSetenv {
+	return &ParallelSetenv{}
+}
+
+// GetID returns parallel_setenv.
+func (lr *ParallelSetenv) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check verifies if aNode is not a parallel test calling os.Setenv. If
+// verification fails lrp creates a new report.
+func (lr *ParallelSetenv) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode, a test or a subtest, does not both call
+// t.Parallel() and os.Setenv(), os.Unsetenv() or os.Clearenv(). If verification
+// fails lrp creates a new report for each change of the environment.
+func (lr *ParallelSetenv) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	body := testBody(info, aNode)
+	if body == nil {
+		return
+	}
+	parallel := false
+	type setenv struct {
+		ce   *ast.CallExpr
+		name string
+	}
+	var setenvs []setenv
+	inspectTest(info, body, func(ce *ast.CallExpr) {
+		if matchTBCall(info, ce, "Parallel") {
+			parallel = true
+		}
+		for _, name := range envSetters {
+			if checker.MatchPkgFuncCall(info, ce, "os", name) {
+				setenvs = append(setenvs, setenv{ce, name})
+			}
+		}
+	})
+	if !parallel {
+		return
+	}
+	for _, s := range setenvs {
+		lrp.AddItem(fs.Position(s.ce.Pos()), lr.GetID(), "os."+s.name+"() in a parallel test is disallowed.")
+	}
+}
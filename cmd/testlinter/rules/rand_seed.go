@@ -0,0 +1,145 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// randFuncs are the functions of math/rand using the shared source, which is
+// seeded by rand.Seed().
+var randFuncs = []string{
+	"ExpFloat64", "Float32", "Float64", "Int", "Int31", "Int31n", "Int63", "Int63n", "Intn",
+	"NormFloat64", "Perm", "Read", "Shuffle", "Uint32", "Uint64",
+}
+
+// RandSeed requires that table tests using math/rand have a fixed seed, so
+// that their cases are reproducible. Only the seeds in the test function are
+// recognised, not the ones in TestMain or init.
+type RandSeed struct{}
+
+// NewRandSeed creates and returns a RandSeed object.
+func NewRandSeed() *RandSeed {
+	return &RandSeed{}
+}
+
+// GetID returns rand_seed.
+func (lr *RandSeed) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check verifies if aNode is not a table test using math/rand without a fixed
+// seed. If verification fails lrp creates a new report.
+func (lr *RandSeed) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode, a table test, does not seed math/rand with a
+// non-constant seed, with rand.Seed() or rand.NewSource(), nor uses the shared
+// source of math/rand without calling rand.Seed(). The uses of a source seeded
+// with a non-constant seed are not reported, as the seed itself is. A table
+// test ranges over a literal, or a slice, array or map of structs, or runs
+// subtests in a loop. If verification fails lrp creates a new report.
+func (lr *RandSeed) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	fn, ok := aNode.(*ast.FuncDecl)
+	if !ok || fn.Body == nil || !strings.HasPrefix(fn.Name.Name, "Test") || !isTableTest(info, fn.Body) {
+		return
+	}
+
+	seeded := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if ce, ok := n.(*ast.CallExpr); ok && len(ce.Args) == 1 &&
+			checker.MatchPkgFuncCall(info, ce, "math/rand", "Seed") {
+			seeded = true
+		}
+		return true
+	})
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		for _, name := range []string{"Seed", "NewSource"} {
+			if len(ce.Args) == 1 && checker.MatchPkgFuncCall(info, ce, "math/rand", name) && !isConstant(info, ce.Args[0]) {
+				lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(),
+					"rand."+name+"() without a fixed seed is disallowed in table tests.")
+			}
+		}
+		if seeded {
+			return true
+		}
+		for _, name := range randFuncs {
+			if checker.MatchPkgFuncCall(info, ce, "math/rand", name) {
+				lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(),
+					"rand."+name+"() without a fixed seed is disallowed in table tests, please call rand.Seed().")
+			}
+		}
+		return true
+	})
+}
+
+// isTableTest returns true if the test body ranges over test cases: over a
+// literal, or a slice, array or map of structs, or over anything to run
+// subtests.
+func isTableTest(info *types.Info, body *ast.BlockStmt) bool {
+	table := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		rs, ok := n.(*ast.RangeStmt)
+		if !ok || table {
+			return !table
+		}
+		if _, ok := rs.X.(*ast.CompositeLit); ok || isStructs(info, rs.X) {
+			table = true
+			return false
+		}
+		ast.Inspect(rs.Body, func(n ast.Node) bool {
+			if ce, ok := n.(*ast.CallExpr); ok && subtest(info, ce) != nil {
+				table = true
+			}
+			return !table
+		})
+		return !table
+	})
+	return table
+}
+
+// isStructs returns true if the type of the expression e is a slice, an array
+// or a map of structs, or of pointers to structs.
+func isStructs(info *types.Info, e ast.Expr) bool {
+	if info == nil {
+		return false
+	}
+	var elem types.Type
+	switch t := info.TypeOf(e).(type) {
+	case *types.Slice:
+		elem = t.Elem()
+	case *types.Array:
+		elem = t.Elem()
+	case *types.Map:
+		elem = t.Elem()
+	default:
+		return false
+	}
+	if p, ok := elem.Underlying().(*types.Pointer); ok {
+		elem = p.Elem()
+	}
+	_, ok := elem.Underlying().(*types.Struct)
+	return ok
+}
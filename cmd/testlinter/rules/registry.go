@@ -0,0 +1,81 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// ruleFactories creates each rule by ID from the JSON object of its
+// parameters, which is empty if none are configured.
+var ruleFactories = map[string]func(params json.RawMessage) (checker.Rule, error){
+	"skip_issue":      newSkipByIssueFromParams,
+	"short_skip":      withoutParams(func() checker.Rule { return NewSkipByShort() }),
+	"no_short":        withoutParams(func() checker.Rule { return NewNoShort() }),
+	"no_sleep":        withoutParams(func() checker.Rule { return NewNoSleep() }),
+	"no_goroutine":    withoutParams(func() checker.Rule { return NewNoGoroutine() }),
+	"parallel_setenv": withoutParams(func() checker.Rule { return NewParallelSetenv() }),
+	"fatal_goroutine": withoutParams(func() checker.Rule { return NewFatalGoroutine() }),
+	"no_network":      withoutParams(func() checker.Rule { return NewNoNetwork() }),
+	"rand_seed":       withoutParams(func() checker.Rule { return NewRandSeed() }),
+	"temp_dir_error":  withoutParams(func() checker.Rule { return NewTempDirError() }),
+}
+
+// NewRule creates and returns the rule with the ID id, configured by params,
+// the JSON object of its parameters if any.
+func NewRule(id string, params json.RawMessage) (checker.Rule, error) {
+	factory, ok := ruleFactories[id]
+	if !ok {
+		var ids []string
+		for id := range ruleFactories {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return nil, fmt.Errorf("unknown rule %q, expected one of %s", id, strings.Join(ids, ", "))
+	}
+	rule, err := factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters of rule %s: %v", id, err)
+	}
+	return rule, nil
+}
+
+// withoutParams returns a factory of the rules created by newRule, which have
+// no parameters.
+func withoutParams(newRule func() checker.Rule) func(params json.RawMessage) (checker.Rule, error) {
+	return func(params json.RawMessage) (checker.Rule, error) {
+		if err := decodeParams(params, &struct{}{}); err != nil {
+			return nil, err
+		}
+		return newRule(), nil
+	}
+}
+
+// decodeParams decodes the JSON object params, if any, into v, rejecting the
+// parameters which v does not have.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	d := json.NewDecoder(bytes.NewReader(params))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
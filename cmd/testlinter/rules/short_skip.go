@@ -67,7 +67,7 @@ func (lr *ShortSkip) GetID() string {
 func (lr *ShortSkip) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
 	if fn, isFn := aNode.(*ast.FuncDecl); isFn && strings.HasPrefix(fn.Name.Name, "Test") {
 		if len(fn.Body.List) == 0 {
-			lrp.AddItem(fs.Position(aNode.Pos()), lr.GetID(), "Missing either 'if testing.Short() { t.Skip() }' or 'if !testing.Short() {}'")
+			lr.addMissing(fn, fs, lrp)
 		} else if len(fn.Body.List) == 1 {
 			if ifStmt, ok := fn.Body.List[0].(*ast.IfStmt); ok {
 				if uExpr, ok := ifStmt.Cond.(*ast.UnaryExpr); ok {
@@ -93,6 +93,26 @@ func (lr *ShortSkip) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Repor
 				}
 			}
 		}
-		lrp.AddItem(fs.Position(aNode.Pos()), lr.GetID(), "Missing either 'if testing.Short() { t.Skip() }' or 'if !testing.Short() {}'")
+		lr.addMissing(fn, fs, lrp)
 	}
 }
+
+// addMissing reports that the test fn misses the check of the short mode,
+// with a fix which adds 'if testing.Short() { t.Skip() }' to the test.
+func (lr *ShortSkip) addMissing(fn *ast.FuncDecl, fs *token.FileSet, lrp *checker.Report) {
+	msg := "Missing either 'if testing.Short() { t.Skip() }' or 'if !testing.Short() {}'"
+	params := fn.Type.Params.List
+	// The fix is only suggested if it satisfies the rule, which expects t.
+	if len(params) != 1 || len(params[0].Names) != 1 || params[0].Names[0].Name != "t" {
+		lrp.AddItem(fs.Position(fn.Pos()), lr.GetID(), msg)
+		return
+	}
+	lrp.AddFix(fs.Position(fn.Pos()), lr.GetID(), msg, checker.SuggestedFix{
+		Message: "Skip the test in short mode",
+		Edits: []checker.TextEdit{{
+			Offset:  fs.Position(fn.Body.Lbrace).Offset + 1,
+			End:     fs.Position(fn.Body.Lbrace).Offset + 1,
+			NewText: "\nif testing.Short() {\nt.Skip(\"skipping " + fn.Name.Name + " in short mode.\")\n}",
+		}},
+	})
+}
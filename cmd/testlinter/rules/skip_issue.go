@@ -15,8 +15,12 @@
 package rules
 
 import (
+	"encoding/json"
+	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
+	"regexp"
 
 	"istio.io/tools/pkg/checker"
 )
@@ -26,15 +30,48 @@ import (
 // For example, this is a valid call,
 // t.Skip("https://github.com/istio/istio/issues/6012")
 // t.SkipNow() and t.Skipf() are not allowed.
+// Calls in nested blocks and closures, e.g. subtests, are checked too, on any testing.TB such
+// as b in benchmarks, except for skips in short mode, i.e. directly in if testing.Short() {}.
 type SkipIssue struct {
-	skipArgsRegex string // Defines arg in t.Skip() that should match.
+	skipArgsRegexes []string // Defines arg in t.Skip() that should match one of.
 }
 
-// NewSkipByIssue creates and returns a SkipIssue object.
+// skipIssueParams are the parameters of SkipIssue in configurations.
+type skipIssueParams struct {
+	// IssuePatterns are the regular expressions of the issue URLs, one of
+	// which arg in t.Skip() should match.
+	IssuePatterns []string `json:"issue_patterns"`
+}
+
+// NewSkipByIssue creates and returns a SkipIssue object, which allows issues of
+// https://github.com/istio/istio.
 func NewSkipByIssue() *SkipIssue {
 	return &SkipIssue{
-		skipArgsRegex: `https:\/\/github\.com\/istio\/istio\/issues\/[0-9]+`,
+		skipArgsRegexes: []string{`https:\/\/github\.com\/istio\/istio\/issues\/[0-9]+`},
+	}
+}
+
+// NewSkipByIssuePatterns creates and returns a SkipIssue object, which allows
+// the issue URLs matching one of the regular expressions patterns.
+func NewSkipByIssuePatterns(patterns []string) (*SkipIssue, error) {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid issue pattern %q: %v", pattern, err)
+		}
+	}
+	return &SkipIssue{skipArgsRegexes: patterns}, nil
+}
+
+// newSkipByIssueFromParams creates a SkipIssue object from its parameters.
+func newSkipByIssueFromParams(params json.RawMessage) (checker.Rule, error) {
+	var p skipIssueParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
 	}
+	if len(p.IssuePatterns) == 0 {
+		return NewSkipByIssue(), nil
+	}
+	return NewSkipByIssuePatterns(p.IssuePatterns)
 }
 
 // GetID returns skip_by_issue_rule.
@@ -50,15 +87,50 @@ func (lr *SkipIssue) GetID() string {
 // t.SkipNow(),
 // t.Skipf("https://istio.io/%d", x).
 func (lr *SkipIssue) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
-	if fn, isFn := aNode.(*ast.FuncDecl); isFn {
-		for _, bd := range fn.Body.List {
-			if ok, _ := matchFunc(bd, "t", "SkipNow"); ok {
-				lrp.AddItem(fs.Position(bd.Pos()), lr.GetID(), "Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue.")
-			} else if ok, _ := matchFunc(bd, "t", "Skipf"); ok {
-				lrp.AddItem(fs.Position(bd.Pos()), lr.GetID(), "Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue.")
-			} else if ok, fcall := matchFunc(bd, "t", "Skip"); ok && !matchFuncArgs(fcall, lr.skipArgsRegex) {
-				lrp.AddItem(fs.Position(bd.Pos()), lr.GetID(), "Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue.")
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies the skips in the function aNode, including in its nested
+// blocks and closures, as Check does. Skips are recognised on any testing.TB,
+// or on t, b and tb without type information.
+func (lr *SkipIssue) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	fn, isFn := aNode.(*ast.FuncDecl)
+	if !isFn || fn.Body == nil {
+		return
+	}
+	// Skips in short mode do not need an issue.
+	shortSkips := map[*ast.CallExpr]bool{}
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.IfStmt:
+			if call, ok := n.Cond.(*ast.CallExpr); ok && checker.MatchPkgFuncCall(info, call, "testing", "Short") {
+				for _, stmt := range n.Body.List {
+					if exprStmt, ok := stmt.(*ast.ExprStmt); ok {
+						if call, ok := exprStmt.X.(*ast.CallExpr); ok {
+							shortSkips[call] = true
+						}
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if shortSkips[n] {
+				return true
 			}
+			if matchTBCall(info, n, "SkipNow") || matchTBCall(info, n, "Skipf") ||
+				(matchTBCall(info, n, "Skip") && !lr.matchSkipArgs(info, n)) {
+				lrp.AddItem(fs.Position(n.Pos()), lr.GetID(), "Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue.")
+			}
+		}
+		return true
+	})
+}
+
+// matchSkipArgs returns true if args in fcall match one of the issue patterns.
+func (lr *SkipIssue) matchSkipArgs(info *types.Info, fcall *ast.CallExpr) bool {
+	for _, argsR := range lr.skipArgsRegexes {
+		if matchFuncArgs(info, fcall, argsR) {
+			return true
 		}
 	}
+	return false
 }
@@ -0,0 +1,89 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+
+	"istio.io/tools/pkg/checker"
+)
+
+// tempFuncs are the functions creating temporary directories and files, by
+// import path, which fail e.g. if the temporary directory is not writable.
+var tempFuncs = map[string][]string{
+	"io/ioutil": {"TempDir", "TempFile"},
+	"os":        {"MkdirTemp", "CreateTemp"},
+}
+
+// TempDirError requires that the errors of ioutil.TempDir() and the like are
+// not ignored, which makes the tests fail later in confusing ways.
+type TempDirError struct{}
+
+// NewTempDirError creates and returns a TempDirError object.
+func NewTempDirError() *TempDirError {
+	return &TempDirError{}
+}
+
+// GetID returns temp_dir_error.
+func (lr *TempDirError) GetID() string {
+	return GetCallerFileName()
+}
+
+// Check verifies if aNode does not ignore the error of ioutil.TempDir(). If
+// verification fails lrp creates a new report.
+func (lr *TempDirError) Check(aNode ast.Node, fs *token.FileSet, lrp *checker.Report) {
+	lr.CheckTyped(aNode, nil, fs, lrp)
+}
+
+// CheckTyped verifies if aNode does not ignore the error of ioutil.TempDir(),
+// ioutil.TempFile(), os.MkdirTemp() or os.CreateTemp(), by discarding the
+// results of the call or assigning the error to _. If verification fails lrp
+// creates a new report.
+func (lr *TempDirError) CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *checker.Report) {
+	var call ast.Expr
+	switch n := aNode.(type) {
+	case *ast.ExprStmt:
+		call = n.X
+	case *ast.AssignStmt:
+		if len(n.Lhs) == 2 && len(n.Rhs) == 1 && isBlank(n.Lhs[1]) {
+			call = n.Rhs[0]
+		}
+	case *ast.ValueSpec:
+		if len(n.Names) == 2 && len(n.Values) == 1 && isBlank(n.Names[1]) {
+			call = n.Values[0]
+		}
+	}
+	ce, ok := call.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	for pkgPath, names := range tempFuncs {
+		for _, name := range names {
+			if checker.MatchPkgFuncCall(info, ce, pkgPath, name) {
+				lrp.AddItem(fs.Position(ce.Pos()), lr.GetID(),
+					"Ignoring the error of "+path.Base(pkgPath)+"."+name+"() is disallowed.")
+			}
+		}
+	}
+}
+
+// isBlank returns true if e is the blank identifier _.
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
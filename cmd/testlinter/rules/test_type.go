@@ -0,0 +1,78 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TestType is type ID of tests
+type TestType int
+
+// All types of tests to parse.
+const (
+	UnitTest  TestType = iota // UnitTest == 0
+	IntegTest TestType = iota // IntegTest == 1
+	E2eTest   TestType = iota // E2eTest == 2
+)
+
+// testTypeNames maps the names of the test types in configurations to them.
+var testTypeNames = map[string]TestType{
+	"unit":        UnitTest,
+	"integration": IntegTest,
+	"e2e":         E2eTest,
+}
+
+// ParseTestType returns the test type named name, one of unit, integration
+// and e2e.
+func ParseTestType(name string) (TestType, error) {
+	testType, ok := testTypeNames[name]
+	if !ok {
+		return UnitTest, fmt.Errorf("unknown test type %q, expected unit, integration or e2e", name)
+	}
+	return testType, nil
+}
+
+// TestTypeOf returns the type of the test file at path absp, and false if absp
+// is not a go test file.
+// If one of the following cases meet, path absp is a valid path to test file.
+// (1) e2e test file
+// .../e2e/.../*_test.go
+// (2) integration test file
+// .../integration/.../*_test.go
+// .../integration/.../*_integ_test.go
+// .../*_integ_test.go
+// (3) unit test file
+// .../*_test.go
+func TestTypeOf(absp string) (TestType, bool) {
+	paths := strings.Split(absp, "/")
+	if len(paths) == 0 || !strings.HasSuffix(absp, "_test.go") {
+		return UnitTest, false
+	}
+
+	for _, path := range paths {
+		if path == "e2e" {
+			return E2eTest, true
+		} else if path == "integration" {
+			return IntegTest, true
+		}
+	}
+	if strings.HasSuffix(paths[len(paths)-1], "_integ_test.go") {
+		// Integration tests can be in non integration directories.
+		return IntegTest, true
+	}
+	return UnitTest, true
+}
@@ -16,12 +16,14 @@ package rules
 
 import (
 	"go/ast"
-	"go/token"
+	"go/types"
 	"log"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
+
+	"istio.io/tools/pkg/checker"
 )
 
 // GetCallerFileName returns filename of caller without file extension.
@@ -48,32 +50,93 @@ func MatchCallExpr(ce *ast.CallExpr, pn string, mn string) bool {
 	return false
 }
 
-// matchFuncArgs returns true if args in fcall matches argsR. argsR is regex.
-func matchFuncArgs(fcall *ast.CallExpr, argsR string) bool {
-	if len(fcall.Args) == 1 && len(argsR) > 0 {
-		if blit, ok := fcall.Args[0].(*ast.BasicLit); ok {
-			if blit.Kind == token.STRING {
-				matched, _ := regexp.MatchString(argsR, blit.Value)
-				return matched
-			}
+// matchFuncArgs returns true if the single arg in fcall is a constant string
+// matching argsR, a regex. Without type information, the arg should be a
+// string literal.
+func matchFuncArgs(info *types.Info, fcall *ast.CallExpr, argsR string) bool {
+	if len(fcall.Args) != 1 || len(argsR) == 0 {
+		return false
+	}
+	arg, ok := checker.StringValue(info, fcall.Args[0])
+	if !ok {
+		return false
+	}
+	matched, _ := regexp.MatchString(argsR, arg)
+	return matched
+}
+
+// tbNames are the usual names of testing.TB values, by which calls on them are
+// recognised without type information.
+var tbNames = map[string]bool{"t": true, "b": true, "tb": true}
+
+// matchTBCall returns true if ce calls the method mn of a testing.TB, such as
+// *testing.T or *testing.B. Without type information, the receiver should be
+// named t, b or tb.
+func matchTBCall(info *types.Info, ce *ast.CallExpr, mn string) bool {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != mn {
+		return false
+	}
+	if f := checker.CalledFunc(info, ce); f != nil {
+		// The methods of testing.TB are implemented by an unexported type
+		// embedded in T and B.
+		return f.Pkg() != nil && f.Pkg().Path() == "testing" && f.Type().(*types.Signature).Recv() != nil
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && tbNames[id.Name]
+}
+
+// testBody returns the body of the test defined by aNode, a function
+// declaration or a call of t.Run with a function literal, or nil if aNode
+// defines none.
+func testBody(info *types.Info, aNode ast.Node) *ast.BlockStmt {
+	switch n := aNode.(type) {
+	case *ast.FuncDecl:
+		return n.Body
+	case *ast.CallExpr:
+		if fl := subtest(info, n); fl != nil {
+			return fl.Body
 		}
 	}
-	return false
+	return nil
+}
+
+// subtest returns the function literal of the subtest run by ce, if ce is a
+// call of t.Run.
+func subtest(info *types.Info, ce *ast.CallExpr) *ast.FuncLit {
+	if len(ce.Args) != 2 || !matchTBCall(info, ce, "Run") {
+		return nil
+	}
+	fl, _ := ce.Args[1].(*ast.FuncLit)
+	return fl
+}
+
+// inspectTest calls f for each call of the test body, except the calls of its
+// subtests, which are tests of their own.
+func inspectTest(info *types.Info, body ast.Node, f func(ce *ast.CallExpr)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		f(ce)
+		if subtest(info, ce) != nil {
+			inspectTest(info, ce.Fun, f)
+			inspectTest(info, ce.Args[0], f)
+			return false
+		}
+		return true
+	})
 }
 
-// matchFunc returns true if bd matches packageName and methodName, and returns CallExpr
-// element in bd for args check.
-func matchFunc(bd ast.Stmt, packageName string, methodName string) (bool, *ast.CallExpr) {
-	if exprStmt, ok := bd.(*ast.ExprStmt); ok {
-		if call, ok := exprStmt.X.(*ast.CallExpr); ok {
-			if fun, ok := call.Fun.(*ast.SelectorExpr); ok {
-				if astid, ok := fun.X.(*ast.Ident); ok {
-					if astid.String() == packageName && fun.Sel.String() == methodName {
-						return true, call
-					}
-				}
-			}
+// isConstant returns true if the expression e is a constant. Without type
+// information, e should be a literal.
+func isConstant(info *types.Info, e ast.Expr) bool {
+	if info != nil {
+		if tv, ok := info.Types[e]; ok {
+			return tv.Value != nil
 		}
 	}
-	return false, nil
+	_, ok := e.(*ast.BasicLit)
+	return ok
 }
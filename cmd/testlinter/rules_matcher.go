@@ -18,51 +18,61 @@ import (
 	"os"
 	"strings"
 
+	"istio.io/tools/cmd/testlinter/rules"
 	"istio.io/tools/pkg/checker"
 )
 
 // TestType is type ID of tests
-type TestType int
+type TestType = rules.TestType
 
 // All types of tests to parse.
 const (
-	UnitTest  TestType = iota // UnitTest == 0
-	IntegTest TestType = iota // IntegTest == 1
-	E2eTest   TestType = iota // E2eTest == 2
+	UnitTest  = rules.UnitTest
+	IntegTest = rules.IntegTest
+	E2eTest   = rules.E2eTest
 )
 
 // RulesMatcher filters out test files and detects test type.
 type RulesMatcher struct {
+	// config, if set, overrides the rules of the test types and the detection
+	// of the test types, and excludes rules from files.
+	config *config
 }
 
-// GetRules checks path absp and decides whether absp is a test file. It returns true and test type
-// for a test file. If path absp should be skipped, it returns false.
-// If one of the following cases meet, path absp is a valid path to test file.
-// (1) e2e test file
-// .../e2e/.../*_test.go
-// (2) integration test file
-// .../integration/.../*_test.go
-// .../integration/.../*_integ_test.go
-// .../*_integ_test.go
-// (3) unit test file
-// .../*_test.go
+// GetRules checks path absp and decides whether absp is a test file, and
+// returns the rules of its test type, see rules.TestTypeOf. If path absp
+// should be skipped, it returns no rules.
 func (rf *RulesMatcher) GetRules(absp string, info os.FileInfo) []checker.Rule {
 	// Skip path which is not go test file or is a directory.
-	paths := strings.Split(absp, "/")
-	if len(paths) == 0 || info.IsDir() || !strings.HasSuffix(absp, "_test.go") {
+	testType, ok := rf.testTypeOf(absp)
+	if !ok || info.IsDir() {
 		return []checker.Rule{}
 	}
+	if rf.config == nil {
+		return LintRulesList[testType]
+	}
+	lintRules, ok := rf.config.rules[testType]
+	if !ok {
+		lintRules = LintRulesList[testType]
+	}
+	return rf.config.exclusions.Filter(absp, lintRules)
+}
 
-	for _, path := range paths {
-		if path == "e2e" {
-			return LintRulesList[E2eTest]
-		} else if path == "integration" {
-			return LintRulesList[IntegTest]
-		}
+// testTypeOf returns the type of the test file at path absp, and false if absp
+// is not a go test file.
+func (rf *RulesMatcher) testTypeOf(absp string) (TestType, bool) {
+	if rf.config == nil || rf.config.testTypes == nil {
+		return rules.TestTypeOf(absp)
+	}
+	if !strings.HasSuffix(absp, "_test.go") {
+		return UnitTest, false
 	}
-	if strings.HasSuffix(paths[len(paths)-1], "_integ_test.go") {
-		// Integration tests can be in non integration directories.
-		return LintRulesList[IntegTest]
+	for _, testType := range []TestType{E2eTest, IntegTest} {
+		for _, glob := range rf.config.testTypes[testType] {
+			if checker.MatchGlob(glob, absp) {
+				return testType, true
+			}
+		}
 	}
-	return LintRulesList[UnitTest]
+	return UnitTest, true
 }
@@ -0,0 +1,118 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testdata
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParallelSetenv(t *testing.T) {
+	t.Parallel()
+	os.Setenv("FLAKY", "1")
+}
+
+func TestParallelSubtestSetenv(t *testing.T) {
+	os.Setenv("FLAKY", "1")
+	t.Run("parallel", func(t *testing.T) {
+		t.Parallel()
+		defer func() {
+			_ = os.Unsetenv("FLAKY")
+		}()
+	})
+}
+
+func TestFatalGoroutine(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := net.Dial("tcp", "127.0.0.1:8080"); err != nil {
+			t.Fatal(err)
+		}
+		t.Error("t.Error does not stop the goroutine")
+		t.Run("subtest", func(t *testing.T) {
+			t.Fatal("subtests run in their own goroutine")
+		})
+	}()
+	<-done
+	t.Fatal("the goroutine of the test")
+}
+
+func TestNetwork(t *testing.T) {
+	if _, err := http.Get("https://example.com/"); err != nil {
+		t.Error(err)
+	}
+	if _, err := http.Get("http://localhost:8080/"); err != nil {
+		t.Error(err)
+	}
+	if _, err := net.Dial("tcp", "example.com:80"); err != nil {
+		t.Error(err)
+	}
+	if _, err := net.DialTimeout("tcp", "[::1]:80", time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRandTable(t *testing.T) {
+	rand.Seed(time.Now().UnixNano())
+	for _, size := range []int{1, 2} {
+		if rand.Intn(size) >= size {
+			t.Error("out of range")
+		}
+	}
+}
+
+func TestRandFixedSeed(t *testing.T) {
+	rand.Seed(42)
+	tests := []int{1, 2}
+	for _, size := range tests {
+		size := size
+		t.Run("", func(t *testing.T) {
+			if rand.Intn(size) >= size {
+				t.Error("out of range")
+			}
+		})
+	}
+}
+
+func TestRandNoTable(t *testing.T) {
+	if rand.Intn(2) >= 2 {
+		t.Error("out of range")
+	}
+}
+
+func TestTempDirError(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "flaky")
+	defer os.RemoveAll(dir)
+	f, err := ioutil.TempFile(dir, "flaky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ioutil.TempFile(dir, "flaky")
+}
+
+func TestRandUnseeded(t *testing.T) {
+	for _, size := range []int{1, 2} {
+		if rand.Intn(size) >= size {
+			t.Error("out of range")
+		}
+	}
+}
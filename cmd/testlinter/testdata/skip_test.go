@@ -0,0 +1,64 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testdata
+
+import (
+	"testing"
+)
+
+// nolint: testlinter
+func TestNestedSkip(t *testing.T) {
+	if Count(1) != 1 {
+		t.Skip("invalid skip in a block.")
+	}
+	t.Run("subtest", func(t *testing.T) {
+		t.Skipf("invalid skip in a subtest %d.", 1)
+	})
+	t.Run("subtest", func(st *testing.T) {
+		st.SkipNow()
+	})
+	t.Run("subtest", func(t *testing.T) {
+		t.Skip("https://github.com/istio/istio/issues/6041")
+	})
+}
+
+// nolint: testlinter
+func BenchmarkSkip(b *testing.B) {
+	b.Skip("invalid skip in a benchmark.")
+}
+
+func skipHelper(tb testing.TB) {
+	tb.Skip("invalid skip in a helper.")
+}
+
+type skipper struct{}
+
+func (skipper) Skip(reason string) {}
+
+// nolint: testlinter
+func TestNotSkip(t *testing.T) {
+	var s skipper
+	s.Skip("not a testing.TB.")
+	if testing.Short() {
+		t.Skip("skipping in short mode.")
+	}
+}
+
+const skipIssue = "https://github.com/istio/istio/issues/6042"
+
+// nolint: testlinter
+func TestSkipConstant(t *testing.T) {
+	t.Skip(skipIssue)
+}
@@ -0,0 +1,48 @@
+// Copyright Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testdata
+
+import (
+	"testing"
+	"time"
+)
+
+// nolint: testlinter
+func TestSuppressedLines(t *testing.T) {
+	//lint:ignore no_sleep waits for the server to start.
+	time.Sleep(100 * time.Millisecond)
+	time.Sleep(100 * time.Millisecond) //lint:ignore no_sleep waits for the server to stop.
+	time.Sleep(100 * time.Millisecond)
+}
+
+//lint:ignore no_sleep,no_goroutine the whole test is slow on purpose.
+func TestSuppressedBlock(t *testing.T) {
+	go SetCount()
+	time.Sleep(100 * time.Millisecond)
+}
+
+// nolint: testlinter
+func TestSuppressedWithoutReason(t *testing.T) {
+	//lint:ignore no_sleep
+	time.Sleep(100 * time.Millisecond)
+}
+
+// nolint: testlinter
+func TestUnusedSuppression(t *testing.T) {
+	//lint:ignore no_sleep does not sleep anymore.
+	SetCount()
+	//lint:ignore SA1000 a directive for another linter.
+	SetCount()
+}
@@ -31,7 +31,6 @@ func getAbsPath(path string) string {
 }
 
 func clearLintRulesList() {
-	checker.IgnoreTestLinterData = false
 	delete(LintRulesList, UnitTest)
 	delete(LintRulesList, IntegTest)
 	delete(LintRulesList, E2eTest)
@@ -42,8 +41,34 @@ func TestUnitTestSkipByIssueRule(t *testing.T) {
 	LintRulesList[UnitTest] = []checker.Rule{rules.NewSkipByIssue()}
 
 	rpts, _ := getReport([]string{"testdata/"})
-	expectedRpts := []string{getAbsPath("testdata/unit_test.go") +
-		":24:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)"}
+	expectedRpts := []string{
+		getAbsPath("testdata/skip_test.go") + ":24:3:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/skip_test.go") + ":27:3:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/skip_test.go") + ":30:3:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/skip_test.go") + ":39:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/skip_test.go") + ":43:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/unit_test.go") + ":24:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestUnitTestSkipByIssuePatternsRule(t *testing.T) {
+	clearLintRulesList()
+	rule, err := rules.NewSkipByIssuePatterns([]string{`^https://github\.com/istio/istio/issues/604[12]$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	LintRulesList[UnitTest] = []checker.Rule{rule}
+
+	rpts, _ := getReport([]string{"testdata/skip_test.go"})
+	expectedRpts := []string{
+		getAbsPath("testdata/skip_test.go") + ":24:3:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/skip_test.go") + ":27:3:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/skip_test.go") + ":30:3:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/skip_test.go") + ":39:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)",
+		getAbsPath("testdata/skip_test.go") + ":43:2:Only t.Skip() is allowed and t.Skip() should contain an url to GitHub issue. (skip_issue)"}
 
 	if !reflect.DeepEqual(rpts, expectedRpts) {
 		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
@@ -55,7 +80,8 @@ func TestUnitTestNoShortRule(t *testing.T) {
 	LintRulesList[UnitTest] = []checker.Rule{rules.NewNoShort()}
 
 	rpts, _ := getReport([]string{"testdata/"})
-	expectedRpts := []string{getAbsPath("testdata/unit_test.go") + ":48:5:testing.Short() is disallowed. (no_short)"}
+	expectedRpts := []string{getAbsPath("testdata/skip_test.go") + ":54:5:testing.Short() is disallowed. (no_short)",
+		getAbsPath("testdata/unit_test.go") + ":48:5:testing.Short() is disallowed. (no_short)"}
 
 	if !reflect.DeepEqual(rpts, expectedRpts) {
 		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
@@ -67,7 +93,12 @@ func TestUnitTestNoSleepRule(t *testing.T) {
 	LintRulesList[UnitTest] = []checker.Rule{rules.NewNoSleep()}
 
 	rpts, _ := getReport([]string{"testdata/"})
-	expectedRpts := []string{getAbsPath("testdata/unit_test.go") + ":66:2:time.Sleep() is disallowed. (no_sleep)"}
+	expectedRpts := []string{getAbsPath("testdata/alias_test.go") + ":28:2:time.Sleep() is disallowed. (no_sleep)",
+		getAbsPath("testdata/suppress_test.go") + ":27:2:time.Sleep() is disallowed. (no_sleep)",
+		getAbsPath("testdata/suppress_test.go") + ":39:2:time.Sleep() is disallowed. (no_sleep)",
+		getAbsPath("testdata/suppress_test.go") +
+			":38:2:lint:ignore directive needs a reason: //lint:ignore <rule-id> <reason> (lint_ignore)",
+		getAbsPath("testdata/unit_test.go") + ":66:2:time.Sleep() is disallowed. (no_sleep)"}
 
 	if !reflect.DeepEqual(rpts, expectedRpts) {
 		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
@@ -79,7 +110,107 @@ func TestUnitTestNoGoroutineRule(t *testing.T) {
 	LintRulesList[UnitTest] = []checker.Rule{rules.NewNoGoroutine()}
 
 	rpts, _ := getReport([]string{"testdata/"})
-	expectedRpts := []string{getAbsPath("testdata/unit_test.go") + ":75:2:goroutine is disallowed. (no_goroutine)"}
+	expectedRpts := []string{getAbsPath("testdata/flaky_test.go") + ":44:2:goroutine is disallowed. (no_goroutine)",
+		getAbsPath("testdata/unit_test.go") + ":75:2:goroutine is disallowed. (no_goroutine)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestUnitTestParallelSetenvRule(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewParallelSetenv()}
+
+	rpts, _ := getReport([]string{"testdata/"})
+	expectedRpts := []string{getAbsPath("testdata/flaky_test.go") + ":29:2:os.Setenv() in a parallel test is disallowed. (parallel_setenv)",
+		getAbsPath("testdata/flaky_test.go") + ":37:8:os.Unsetenv() in a parallel test is disallowed. (parallel_setenv)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestUnitTestFatalGoroutineRule(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewFatalGoroutine()}
+
+	rpts, _ := getReport([]string{"testdata/"})
+	expectedRpts := []string{getAbsPath("testdata/flaky_test.go") +
+		":47:4:t.Fatal() from a goroutine other than the test's is disallowed. (fatal_goroutine)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestUnitTestNoNetworkRule(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewNoNetwork()}
+
+	rpts, _ := getReport([]string{"testdata/"})
+	expectedRpts := []string{getAbsPath("testdata/flaky_test.go") +
+		":59:15:http.Get() to the non-loopback host example.com is disallowed. (no_network)",
+		getAbsPath("testdata/flaky_test.go") + ":65:15:net.Dial() to the non-loopback host example.com is disallowed. (no_network)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestUnitTestRandSeedRule(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewRandSeed()}
+
+	rpts, _ := getReport([]string{"testdata/"})
+	expectedRpts := []string{getAbsPath("testdata/flaky_test.go") +
+		":74:2:rand.Seed() without a fixed seed is disallowed in table tests. (rand_seed)",
+		getAbsPath("testdata/flaky_test.go") +
+			":114:6:rand.Intn() without a fixed seed is disallowed in table tests, please call rand.Seed(). (rand_seed)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestUnitTestTempDirErrorRule(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewTempDirError()}
+
+	rpts, _ := getReport([]string{"testdata/"})
+	expectedRpts := []string{getAbsPath("testdata/flaky_test.go") +
+		":102:12:Ignoring the error of ioutil.TempDir() is disallowed. (temp_dir_error)",
+		getAbsPath("testdata/flaky_test.go") + ":109:2:Ignoring the error of ioutil.TempFile() is disallowed. (temp_dir_error)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+}
+
+func TestUnitTestUnusedSuppressions(t *testing.T) {
+	clearLintRulesList()
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewNoGoroutine()}
+	checker.ReportUnusedSuppressions = true
+	defer func() { checker.ReportUnusedSuppressions = false }()
+
+	rpts, _ := getReport([]string{"testdata/"})
+	expectedRpts := []string{getAbsPath("testdata/flaky_test.go") + ":44:2:goroutine is disallowed. (no_goroutine)",
+		getAbsPath("testdata/unit_test.go") + ":75:2:goroutine is disallowed. (no_goroutine)"}
+
+	if !reflect.DeepEqual(rpts, expectedRpts) {
+		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
+	}
+
+	LintRulesList[UnitTest] = []checker.Rule{rules.NewNoSleep()}
+	rpts, _ = getReport([]string{"testdata/suppress_test.go"})
+	expectedRpts = []string{
+		getAbsPath("testdata/suppress_test.go") + ":27:2:time.Sleep() is disallowed. (no_sleep)",
+		getAbsPath("testdata/suppress_test.go") + ":39:2:time.Sleep() is disallowed. (no_sleep)",
+		getAbsPath("testdata/suppress_test.go") +
+			":38:2:lint:ignore directive needs a reason: //lint:ignore <rule-id> <reason> (lint_ignore)",
+		getAbsPath("testdata/suppress_test.go") +
+			":44:2:lint:ignore directive suppresses no lint error of no_sleep (lint_ignore)",
+	}
 
 	if !reflect.DeepEqual(rpts, expectedRpts) {
 		t.Errorf("lint reports don't match\nReceived: %v\nExpected: %v", rpts, expectedRpts)
@@ -0,0 +1,71 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"istio.io/tools/isotope/convert/pkg/compose"
+	"istio.io/tools/isotope/convert/pkg/graph"
+)
+
+// composeCmd represents the compose command
+var composeCmd = &cobra.Command{
+	Use:   "compose [service-graph.yaml] [output directory]",
+	Short: "Convert service graph YAML to a Docker Compose file",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		inPath := args[0]
+		outDir := args[1]
+
+		serviceImage, err := cmd.PersistentFlags().GetString("service-image")
+		exitIfError(err)
+
+		serviceMaxIdleConnectionsPerHost, err :=
+			cmd.PersistentFlags().GetInt("service-max-idle-connections-per-host")
+		exitIfError(err)
+
+		serviceSeed, err := cmd.PersistentFlags().GetInt64("seed")
+		exitIfError(err)
+
+		includePrometheus, err := cmd.PersistentFlags().GetBool("prometheus")
+		exitIfError(err)
+
+		serviceGraph, err := graph.FromYAMLFile(inPath)
+		exitIfError(err)
+
+		files, err := compose.ServiceGraphToComposeFiles(
+			serviceGraph, serviceImage, serviceMaxIdleConnectionsPerHost,
+			serviceSeed, includePrometheus)
+		exitIfError(err)
+
+		exitIfError(writeFiles(outDir, files))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(composeCmd)
+	composeCmd.PersistentFlags().String(
+		"service-image", "", "the image to run for all services in the graph")
+	composeCmd.PersistentFlags().Int(
+		"service-max-idle-connections-per-host", 0,
+		"maximum number of connections to keep open per host on each service")
+	composeCmd.PersistentFlags().Int64(
+		"seed", 0,
+		"the seed from which each service derives its own for its random decisions "+
+			"(e.g. call probabilities); 0 lets each service seed itself from the current time")
+	composeCmd.PersistentFlags().Bool(
+		"prometheus", false, "include a Prometheus service scraping all services")
+}
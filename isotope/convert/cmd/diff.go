@@ -0,0 +1,60 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/tools/isotope/convert/pkg/diff"
+	"istio.io/tools/isotope/convert/pkg/graph"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [old service-graph.yaml] [new service-graph.yaml]",
+	Short: "Show the semantic differences between two service graphs",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, err := cmd.PersistentFlags().GetString("output")
+		exitIfError(err)
+
+		oldGraph, err := graph.FromYAMLFile(args[0])
+		exitIfError(err)
+		newGraph, err := graph.FromYAMLFile(args[1])
+		exitIfError(err)
+
+		d := diff.Compare(oldGraph, newGraph)
+
+		switch output {
+		case "text":
+			fmt.Print(d)
+		case "json":
+			b, err := json.MarshalIndent(d, "", "  ")
+			exitIfError(err)
+			fmt.Println(string(b))
+		default:
+			exitIfError(fmt.Errorf("unknown output format %q", output))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.PersistentFlags().StringP(
+		"output", "o", "text", `the output format ("text" or "json")`)
+}
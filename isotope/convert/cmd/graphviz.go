@@ -15,9 +15,10 @@
 package cmd
 
 import (
+	"fmt"
 	"io/ioutil"
+	"strings"
 
-	"github.com/ghodss/yaml"
 	"github.com/spf13/cobra"
 
 	"istio.io/tools/isotope/convert/pkg/graph"
@@ -27,26 +28,34 @@ import (
 // graphvizCmd represents the graphviz command
 var graphvizCmd = &cobra.Command{
 	Use:   "graphviz [YAML file] [output file]",
-	Short: "Convert a .yaml file to a Graphviz DOT language file",
+	Short: "Convert a .yaml file to a Graphviz DOT language file or another graph format",
 	Args:  cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		inFileName := args[0]
-		yamlContents, err := ioutil.ReadFile(inFileName)
+		format, err := cmd.PersistentFlags().GetString("format")
 		exitIfError(err)
 
-		var serviceGraph graph.ServiceGraph
-		err = yaml.Unmarshal(yamlContents, &serviceGraph)
+		inFileName := args[0]
+		serviceGraph, err := graph.FromYAMLFile(inFileName)
 		exitIfError(err)
 
-		dotLang, err := graphviz.ServiceGraphToDotLanguage(serviceGraph)
+		output, err := graphviz.ServiceGraphToFormat(
+			serviceGraph, graphviz.Format(format))
 		exitIfError(err)
 
 		outFileName := args[1]
-		err = ioutil.WriteFile(outFileName, []byte(dotLang), 0644)
+		err = ioutil.WriteFile(outFileName, []byte(output), 0644)
 		exitIfError(err)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(graphvizCmd)
+
+	formats := make([]string, 0, len(graphviz.Formats))
+	for _, format := range graphviz.Formats {
+		formats = append(formats, string(format))
+	}
+	graphvizCmd.PersistentFlags().String(
+		"format", string(graphviz.FormatDOT),
+		fmt.Sprintf("the output format (one of %s)", strings.Join(formats, ", ")))
 }
@@ -17,15 +17,20 @@ package cmd
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 
-	"github.com/ghodss/yaml"
 	"github.com/spf13/cobra"
 
 	"istio.io/tools/isotope/convert/pkg/graph"
 	"istio.io/tools/isotope/convert/pkg/kubernetes"
 )
 
+// unnamedClusterFileName is the file of the manifests of the unnamed cluster,
+// i.e. when no service is placed in a cluster.
+const unnamedClusterFileName = "default.yaml"
+
 // kubernetesCmd represents the kubernetes command
 var kubernetesCmd = &cobra.Command{
 	Use:   "kubernetes [service-graph.yaml]",
@@ -52,24 +57,96 @@ var kubernetesCmd = &cobra.Command{
 			cmd.PersistentFlags().GetInt("service-max-idle-connections-per-host")
 		exitIfError(err)
 
+		serviceSeed, err := cmd.PersistentFlags().GetInt64("seed")
+		exitIfError(err)
+
 		clientImage, err := cmd.PersistentFlags().GetString("client-image")
 		exitIfError(err)
 
 		environmentName, err := cmd.PersistentFlags().GetString("environment-name")
 		exitIfError(err)
 
-		yamlContents, err := ioutil.ReadFile(inPath)
+		peerAuthenticationMode, err := cmd.PersistentFlags().GetString("peer-authentication-mode")
+		exitIfError(err)
+
+		trafficManagement, err := cmd.PersistentFlags().GetBool("traffic-management")
+		exitIfError(err)
+
+		requestTimeout, err := cmd.PersistentFlags().GetDuration("request-timeout")
+		exitIfError(err)
+
+		requestRetries, err := cmd.PersistentFlags().GetInt32("request-retries")
 		exitIfError(err)
 
-		var serviceGraph graph.ServiceGraph
-		exitIfError(yaml.Unmarshal(yamlContents, &serviceGraph))
+		outputFormat, err := cmd.PersistentFlags().GetString("output-format")
+		exitIfError(err)
+
+		outputDir, err := cmd.PersistentFlags().GetString("output-dir")
+		exitIfError(err)
 
-		manifests, err := kubernetes.ServiceGraphToKubernetesManifests(
-			serviceGraph, serviceNodeSelector, serviceImage,
-			serviceMaxIdleConnectionsPerHost, clientNodeSelector, clientImage, environmentName)
+		serviceGraph, err := graph.FromYAMLFile(inPath)
 		exitIfError(err)
 
-		fmt.Println(string(manifests))
+		mesh := kubernetes.MeshOptions{
+			PeerAuthenticationMode: peerAuthenticationMode,
+			TrafficManagement:      trafficManagement,
+			RequestTimeout:         requestTimeout,
+			RequestRetries:         requestRetries,
+		}
+
+		switch outputFormat {
+		case "yaml":
+			manifests, err := kubernetes.ServiceGraphToKubernetesManifests(
+				serviceGraph, serviceNodeSelector, serviceImage,
+				serviceMaxIdleConnectionsPerHost, serviceSeed, clientNodeSelector, clientImage, environmentName,
+				mesh)
+			exitIfError(err)
+
+			fmt.Println(string(manifests))
+		case "clusters":
+			if outputDir == "" {
+				exitIfError(fmt.Errorf("--output-dir is required for output format %q", outputFormat))
+			}
+
+			manifestsByCluster, err := kubernetes.ServiceGraphToKubernetesClusterManifests(
+				serviceGraph, serviceNodeSelector, serviceImage,
+				serviceMaxIdleConnectionsPerHost, serviceSeed, clientNodeSelector, clientImage, environmentName,
+				mesh)
+			exitIfError(err)
+
+			files := make(map[string][]byte, len(manifestsByCluster))
+			for cluster, manifests := range manifestsByCluster {
+				name := cluster + ".yaml"
+				if cluster == "" {
+					name = unnamedClusterFileName
+				}
+				if _, ok := files[name]; ok {
+					exitIfError(fmt.Errorf(
+						"the unnamed cluster and a cluster named %q would both be written to %s",
+						strings.TrimSuffix(name, ".yaml"), name))
+				}
+				files[name] = manifests
+			}
+			exitIfError(writeFiles(outputDir, files))
+		case "kustomize", "helm":
+			if outputDir == "" {
+				exitIfError(fmt.Errorf("--output-dir is required for output format %q", outputFormat))
+			}
+
+			convertToFiles := kubernetes.ServiceGraphToKustomization
+			if outputFormat == "helm" {
+				convertToFiles = kubernetes.ServiceGraphToHelmChart
+			}
+			files, err := convertToFiles(
+				serviceGraph, serviceNodeSelector, serviceImage,
+				serviceMaxIdleConnectionsPerHost, serviceSeed, clientNodeSelector, clientImage, environmentName,
+				mesh)
+			exitIfError(err)
+
+			exitIfError(writeFiles(outputDir, files))
+		default:
+			exitIfError(fmt.Errorf("unknown output format %q", outputFormat))
+		}
 	},
 }
 
@@ -80,16 +157,52 @@ func init() {
 	kubernetesCmd.PersistentFlags().Int(
 		"service-max-idle-connections-per-host", 0,
 		"maximum number of connections to keep open per host on each service")
+	kubernetesCmd.PersistentFlags().Int64(
+		"seed", 0,
+		"the seed from which each service derives its own for its random decisions "+
+			"(e.g. call probabilities); 0 lets each service seed itself from the current time")
 	kubernetesCmd.PersistentFlags().String(
 		"client-image", "", "the image to use for the load testing client job")
 	kubernetesCmd.PersistentFlags().String(
 		"environment-name", "NONE", `the environment name for the test ("NONE" or "ISTIO")`)
+	kubernetesCmd.PersistentFlags().String(
+		"peer-authentication-mode", "",
+		`the mutual TLS mode of the service graph namespace ("STRICT", "PERMISSIVE" or "DISABLE"); only used with "ISTIO"`)
+	kubernetesCmd.PersistentFlags().Bool(
+		"traffic-management", false,
+		`generate a DestinationRule and VirtualService for each service; only used with "ISTIO"`)
+	kubernetesCmd.PersistentFlags().Duration(
+		"request-timeout", 0, "the timeout of each call edge in the generated VirtualServices")
+	kubernetesCmd.PersistentFlags().Int32(
+		"request-retries", 0, "the retry attempts of each call edge in the generated VirtualServices")
+	kubernetesCmd.PersistentFlags().String(
+		"output-format", "yaml",
+		`the output format: "yaml" prints all manifests, "clusters" writes the manifests of each `+
+			`cluster to <cluster>.yaml, "kustomize" writes a Kustomize base `+
+			`and "helm" writes a Helm chart to --output-dir`)
+	kubernetesCmd.PersistentFlags().String(
+		"output-dir", "",
+		`the directory to write to for the "clusters", "kustomize" and "helm" output formats`)
 	kubernetesCmd.PersistentFlags().String(
 		"client-node-selector", "", "the node selector for client workloads")
 	kubernetesCmd.PersistentFlags().String(
 		"service-node-selector", "", "the node selector for service workloads")
 }
 
+// writeFiles writes each file in files, keyed by its path relative to dir.
+func writeFiles(dir string, files map[string][]byte) error {
+	for name, contents := range files {
+		filePath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filePath, contents, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func splitByEquals(s string) (k string, v string, err error) {
 	parts := strings.Split(s, "=")
 	if len(parts) != 2 {
@@ -0,0 +1,281 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compose converts service graphs into Docker Compose files.
+package compose
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/tools/isotope/convert/pkg/consts"
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
+)
+
+const (
+	// ComposeFileName is the name of the Docker Compose file.
+	ComposeFileName = "docker-compose.yaml"
+	// PrometheusConfigFileName is the name of the Prometheus configuration
+	// file, only written when Prometheus is included.
+	PrometheusConfigFileName = "prometheus.yml"
+
+	composeFileVersion = "3.3"
+
+	serviceGraphConfigName = "service-graph"
+	defaultNetworkName     = "default"
+
+	prometheusServiceName = "prometheus"
+	prometheusImage       = "prom/prometheus"
+	prometheusPort        = 9090
+	prometheusConfigName  = "prometheus"
+	prometheusConfigPath  = "/etc/prometheus/prometheus.yml"
+	prometheusMetricsPath = "/metrics"
+)
+
+// File is a Docker Compose file.
+type File struct {
+	Version  string             `json:"version"`
+	Services map[string]Service `json:"services"`
+	Configs  map[string]Config  `json:"configs,omitempty"`
+}
+
+// Service is a service of a Docker Compose file.
+type Service struct {
+	Image       string             `json:"image"`
+	Command     []string           `json:"command,omitempty"`
+	Environment map[string]string  `json:"environment,omitempty"`
+	Expose      []string           `json:"expose,omitempty"`
+	Ports       []string           `json:"ports,omitempty"`
+	Configs     []ServiceConfig    `json:"configs,omitempty"`
+	Networks    map[string]Network `json:"networks,omitempty"`
+	Deploy      *Deploy            `json:"deploy,omitempty"`
+}
+
+// Network connects a service to a network.
+type Network struct {
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// ServiceConfig mounts a config in a service's containers.
+type ServiceConfig struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Deploy sets the deployment of a service.
+type Deploy struct {
+	Replicas int32 `json:"replicas"`
+}
+
+// Config is a config backed by a file next to the Docker Compose file.
+type Config struct {
+	File string `json:"file"`
+}
+
+// ServiceGraphToComposeFiles converts a ServiceGraph to a Docker Compose file
+// running a container per replica of each service. The services share the
+// service graph as a config and reach each other by name, as they do in
+// Kubernetes. If includePrometheus is set, a Prometheus service scrapes all of
+// them. It returns the contents of each file keyed by its name.
+func ServiceGraphToComposeFiles(
+	serviceGraph graph.ServiceGraph,
+	serviceImage string,
+	serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64,
+	includePrometheus bool) (map[string][]byte, error) {
+	graphYAML, err := yaml.Marshal(serviceGraph)
+	if err != nil {
+		return nil, err
+	}
+
+	file := File{
+		Version:  composeFileVersion,
+		Services: make(map[string]Service, len(serviceGraph.Services)+1),
+		Configs: map[string]Config{
+			serviceGraphConfigName: {File: consts.ServiceGraphYAMLFileName},
+		},
+	}
+	files := map[string][]byte{consts.ServiceGraphYAMLFileName: graphYAML}
+
+	// Services in other namespaces are called by their fully qualified domain
+	// names, which all services answer to if there are several namespaces.
+	withFQDNs := false
+	for _, service := range serviceGraph.Services {
+		if service.EffectiveNamespace() != consts.ServiceGraphNamespace {
+			withFQDNs = true
+		}
+	}
+
+	for _, service := range serviceGraph.Services {
+		var aliases []string
+		if withFQDNs {
+			aliases = append(aliases, service.FQDN())
+		}
+		if len(service.Versions) == 0 {
+			s := makeService(
+				service, serviceImage, serviceMaxIdleConnectionsPerHost,
+				svc.DeploymentSeed(serviceSeed, service.Name))
+			if len(aliases) > 0 {
+				s.Networks = map[string]Network{defaultNetworkName: {Aliases: aliases}}
+			}
+			file.Services[service.Name] = s
+			continue
+		}
+		// Each version is a separate service which also answers to the name of
+		// the service. Requests are spread over the replicas of all versions,
+		// so version weights are not applied.
+		for _, version := range service.Versions {
+			versionService, err := service.WithVersion(version.Name)
+			if err != nil {
+				return nil, err
+			}
+			name := svc.VersionDeploymentName(service.Name, version.Name)
+			s := makeService(
+				versionService, serviceImage, serviceMaxIdleConnectionsPerHost,
+				svc.DeploymentSeed(serviceSeed, name))
+			s.Environment[consts.ServiceVersionEnvKey] = version.Name
+			s.Networks = map[string]Network{
+				defaultNetworkName: {Aliases: append([]string{service.Name}, aliases...)},
+			}
+			file.Services[name] = s
+		}
+	}
+
+	if includePrometheus {
+		if _, ok := file.Services[prometheusServiceName]; ok {
+			return nil, fmt.Errorf(
+				"cannot include Prometheus: service graph already has a service named %q",
+				prometheusServiceName)
+		}
+		prometheusConfig, err := makePrometheusConfig(serviceGraph)
+		if err != nil {
+			return nil, err
+		}
+		files[PrometheusConfigFileName] = prometheusConfig
+		file.Configs[prometheusConfigName] = Config{File: PrometheusConfigFileName}
+		file.Services[prometheusServiceName] = Service{
+			Image: prometheusImage,
+			Ports: []string{fmt.Sprintf("%d:%d", prometheusPort, prometheusPort)},
+			Configs: []ServiceConfig{
+				{Source: prometheusConfigName, Target: prometheusConfigPath},
+			},
+		}
+	}
+
+	composeYAML, err := yaml.Marshal(file)
+	if err != nil {
+		return nil, err
+	}
+	files[ComposeFileName] = composeYAML
+	return files, nil
+}
+
+func makeService(
+	service svc.Service, serviceImage string,
+	serviceMaxIdleConnectionsPerHost int, serviceSeed int64) Service {
+	command := []string{
+		fmt.Sprintf(
+			"--max-idle-connections-per-host=%v", serviceMaxIdleConnectionsPerHost),
+	}
+	if serviceSeed != 0 {
+		command = append(command, fmt.Sprintf("--seed=%v", serviceSeed))
+	}
+
+	port := strconv.Itoa(consts.ServicePort)
+	s := Service{
+		Image:       serviceImage,
+		Command:     command,
+		Environment: map[string]string{consts.ServiceNameEnvKey: service.Name},
+		Expose:      []string{port},
+		Configs: []ServiceConfig{
+			{
+				Source: serviceGraphConfigName,
+				Target: path.Join(consts.ConfigPath, consts.ServiceGraphYAMLFileName),
+			},
+		},
+		Deploy: &Deploy{Replicas: service.NumReplicas},
+	}
+	// Entrypoints are published on an ephemeral host port, which still allows
+	// running several replicas of them.
+	if service.IsEntrypoint {
+		s.Ports = []string{port}
+	}
+	return s
+}
+
+type prometheusConfig struct {
+	ScrapeConfigs []scrapeConfig `json:"scrape_configs"`
+}
+
+type scrapeConfig struct {
+	JobName        string        `json:"job_name"`
+	MetricsPath    string        `json:"metrics_path"`
+	DNSSDConfigs   []dnsSDConfig `json:"dns_sd_configs"`
+	RelabelConfigs []relabel     `json:"relabel_configs,omitempty"`
+}
+
+// dnsSDConfig discovers every replica of a service through the A records of
+// its name.
+type dnsSDConfig struct {
+	Names []string `json:"names"`
+	Type  string   `json:"type"`
+	Port  int      `json:"port"`
+}
+
+type relabel struct {
+	SourceLabels []string `json:"source_labels"`
+	TargetLabel  string   `json:"target_label"`
+}
+
+func makePrometheusConfig(serviceGraph graph.ServiceGraph) ([]byte, error) {
+	// TCP services expose their metrics on a separate port.
+	var names, tcpNames []string
+	for _, service := range serviceGraph.Services {
+		if service.Type == svctype.ServiceTCP {
+			tcpNames = append(tcpNames, service.Name)
+		} else {
+			names = append(names, service.Name)
+		}
+	}
+	var dnsSDConfigs []dnsSDConfig
+	if len(names) > 0 {
+		dnsSDConfigs = append(dnsSDConfigs,
+			dnsSDConfig{Names: names, Type: "A", Port: consts.ServicePort})
+	}
+	if len(tcpNames) > 0 {
+		dnsSDConfigs = append(dnsSDConfigs,
+			dnsSDConfig{Names: tcpNames, Type: "A", Port: consts.ServiceMetricsPort})
+	}
+	config := prometheusConfig{
+		ScrapeConfigs: []scrapeConfig{
+			{
+				JobName:      "service-graph",
+				MetricsPath:  prometheusMetricsPath,
+				DNSSDConfigs: dnsSDConfigs,
+				RelabelConfigs: []relabel{
+					{
+						SourceLabels: []string{"__meta_dns_name"},
+						TargetLabel:  "service",
+					},
+				},
+			},
+		},
+	}
+	return yaml.Marshal(config)
+}
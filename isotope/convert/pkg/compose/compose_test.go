@@ -0,0 +1,170 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compose
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/tools/isotope/convert/pkg/consts"
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+func TestServiceGraphToComposeFiles(t *testing.T) {
+	serviceGraph := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", NumReplicas: 2},
+		{Name: "b", NumReplicas: 1, IsEntrypoint: true, Script: script.Script{
+			script.RequestCommand{ServiceName: "a"},
+		}},
+	}}
+
+	files, err := ServiceGraphToComposeFiles(serviceGraph, "isotope", 8, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{
+		ComposeFileName, PrometheusConfigFileName, consts.ServiceGraphYAMLFileName} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing file %s", name)
+		}
+	}
+
+	var file File
+	if err := yaml.Unmarshal(files[ComposeFileName], &file); err != nil {
+		t.Fatal(err)
+	}
+	configs := []ServiceConfig{
+		{Source: "service-graph", Target: "/etc/config/service-graph.yaml"},
+	}
+	expected := map[string]Service{
+		"a": {
+			Image:       "isotope",
+			Command:     []string{"--max-idle-connections-per-host=8"},
+			Environment: map[string]string{"SERVICE_NAME": "a"},
+			Expose:      []string{"8080"},
+			Configs:     configs,
+			Deploy:      &Deploy{Replicas: 2},
+		},
+		"b": {
+			Image:       "isotope",
+			Command:     []string{"--max-idle-connections-per-host=8"},
+			Environment: map[string]string{"SERVICE_NAME": "b"},
+			Expose:      []string{"8080"},
+			Ports:       []string{"8080"},
+			Configs:     configs,
+			Deploy:      &Deploy{Replicas: 1},
+		},
+		"prometheus": {
+			Image: "prom/prometheus",
+			Ports: []string{"9090:9090"},
+			Configs: []ServiceConfig{
+				{Source: "prometheus", Target: "/etc/prometheus/prometheus.yml"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, file.Services) {
+		t.Errorf("expected %v; actual %v", expected, file.Services)
+	}
+}
+
+func TestServiceGraphToComposeFiles_Versions(t *testing.T) {
+	serviceGraph := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", Versions: []svc.Version{
+			{Name: "v1", NumReplicas: 2},
+			{Name: "v2", NumReplicas: 1},
+		}},
+	}}
+
+	files, err := ServiceGraphToComposeFiles(serviceGraph, "isotope", 8, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file File
+	if err := yaml.Unmarshal(files[ComposeFileName], &file); err != nil {
+		t.Fatal(err)
+	}
+
+	networks := map[string]Network{"default": {Aliases: []string{"a"}}}
+	configs := []ServiceConfig{
+		{Source: "service-graph", Target: "/etc/config/service-graph.yaml"},
+	}
+	expected := map[string]Service{
+		"a-v1": {
+			Image:       "isotope",
+			Command:     []string{"--max-idle-connections-per-host=8"},
+			Environment: map[string]string{"SERVICE_NAME": "a", "SERVICE_VERSION": "v1"},
+			Expose:      []string{"8080"},
+			Configs:     configs,
+			Networks:    networks,
+			Deploy:      &Deploy{Replicas: 2},
+		},
+		"a-v2": {
+			Image:       "isotope",
+			Command:     []string{"--max-idle-connections-per-host=8"},
+			Environment: map[string]string{"SERVICE_NAME": "a", "SERVICE_VERSION": "v2"},
+			Expose:      []string{"8080"},
+			Configs:     configs,
+			Networks:    networks,
+			Deploy:      &Deploy{Replicas: 1},
+		},
+	}
+	if !reflect.DeepEqual(expected, file.Services) {
+		t.Errorf("expected %v; actual %v", expected, file.Services)
+	}
+}
+
+func TestServiceGraphToComposeFiles_PrometheusNameTaken(t *testing.T) {
+	serviceGraph := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "prometheus"},
+	}}
+	if _, err := ServiceGraphToComposeFiles(serviceGraph, "", 0, 0, true); err == nil {
+		t.Error("expected an error; actual nil")
+	}
+}
+
+func TestServiceGraphToComposeFiles_Namespaces(t *testing.T) {
+	serviceGraph := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", Namespace: "storage"},
+		{Name: "b", Script: script.Script{
+			script.RequestCommand{ServiceName: "a"},
+		}},
+	}}
+
+	files, err := ServiceGraphToComposeFiles(serviceGraph, "isotope", 8, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file File
+	if err := yaml.Unmarshal(files[ComposeFileName], &file); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"a": {"a.storage.svc.cluster.local"},
+		"b": {"b.service-graph.svc.cluster.local"},
+	}
+	actual := make(map[string][]string, len(file.Services))
+	for name, service := range file.Services {
+		actual[name] = service.Networks["default"].Aliases
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+}
@@ -22,6 +22,12 @@ const (
 	ServicePort = 8080
 	// ServicePortName is the name of the service port.
 	ServicePortName = "http-web"
+	// TCPServicePortName is the name of the service port of TCP services, which
+	// the mesh treats as opaque TCP.
+	TCPServicePortName = "tcp-service"
+	// ServiceMetricsPort is the port TCP services expose their metrics on over
+	// HTTP, as their service port does not speak HTTP.
+	ServiceMetricsPort = 8081
 
 	// ServiceGraphNamespace is the name of the namespace that all service graph
 	// related components will live in.
@@ -40,6 +46,9 @@ const (
 	// ServiceNameEnvKey is the key of the environment variable whose value is
 	// the name of the service.
 	ServiceNameEnvKey = "SERVICE_NAME"
+	// ServiceVersionEnvKey is the key of the environment variable whose value
+	// is the name of the version of the service, if it has versions.
+	ServiceVersionEnvKey = "SERVICE_VERSION"
 
 	// FortioMetricsPort is the port on which /metrics is available.
 	FortioMetricsPort = 42422
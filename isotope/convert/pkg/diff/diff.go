@@ -0,0 +1,388 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff compares service graphs semantically, after defaults have been
+// applied, rather than by their YAML.
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+// Change is the kind of change of a call or a sleep.
+type Change string
+
+const (
+	// Added means the item only exists in the new graph.
+	Added Change = "added"
+	// Removed means the item only exists in the old graph.
+	Removed Change = "removed"
+	// Modified means the item exists in both graphs with different settings.
+	Modified Change = "modified"
+)
+
+// Diff describes the changes from an old to a new service graph.
+type Diff struct {
+	AddedServices   []string      `json:"addedServices,omitempty"`
+	RemovedServices []string      `json:"removedServices,omitempty"`
+	ChangedServices []ServiceDiff `json:"changedServices,omitempty"`
+}
+
+// ServiceDiff describes the changes to a service present in both graphs, or
+// to a version present in both services.
+type ServiceDiff struct {
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields,omitempty"`
+	Calls  []CallChange  `json:"calls,omitempty"`
+	Sleeps []SleepChange `json:"sleeps,omitempty"`
+	// Versions are the changes to the versions of the service, by name. Added
+	// and removed versions are reported by the versions field.
+	Versions []ServiceDiff `json:"versions,omitempty"`
+}
+
+// FieldChange is a change to a scalar setting of a service.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// CallChange is a change to a call edge in a service's script. Calls to the
+// same service are matched in the order they appear in the script.
+type CallChange struct {
+	Change  Change `json:"change"`
+	Service string `json:"service"`
+	Old     *Call  `json:"old,omitempty"`
+	New     *Call  `json:"new,omitempty"`
+}
+
+// Call is a request made by a script.
+type Call struct {
+	Step          int    `json:"step"`
+	Concurrent    bool   `json:"concurrent,omitempty"`
+	Size          string `json:"size"`
+	Probability   string `json:"probability"`
+	NewConnection bool   `json:"newConnection,omitempty"`
+}
+
+// SleepChange is a change to a sleep in a service's script. Sleeps are
+// matched in the order they appear in the script.
+type SleepChange struct {
+	Change Change `json:"change"`
+	Index  int    `json:"index"`
+	Old    *Sleep `json:"old,omitempty"`
+	New    *Sleep `json:"new,omitempty"`
+}
+
+// Sleep is a pause in a script.
+type Sleep struct {
+	Step       int    `json:"step"`
+	Concurrent bool   `json:"concurrent,omitempty"`
+	Duration   string `json:"duration"`
+}
+
+// IsEmpty returns true if the graphs are equivalent.
+func (d Diff) IsEmpty() bool {
+	return len(d.AddedServices) == 0 && len(d.RemovedServices) == 0 &&
+		len(d.ChangedServices) == 0
+}
+
+// Compare returns the changes from the old to the new service graph.
+// Services are reported in the order of the graph they are found in.
+func Compare(old, new graph.ServiceGraph) Diff {
+	oldServices := servicesByName(old)
+	newServices := servicesByName(new)
+
+	var d Diff
+	for _, service := range old.Services {
+		if _, ok := newServices[service.Name]; !ok {
+			d.RemovedServices = append(d.RemovedServices, service.Name)
+		}
+	}
+	for _, service := range new.Services {
+		oldService, ok := oldServices[service.Name]
+		if !ok {
+			d.AddedServices = append(d.AddedServices, service.Name)
+			continue
+		}
+		if serviceDiff := compareServices(oldService, service); !serviceDiff.isEmpty() {
+			d.ChangedServices = append(d.ChangedServices, serviceDiff)
+		}
+	}
+	return d
+}
+
+func servicesByName(g graph.ServiceGraph) map[string]svc.Service {
+	services := make(map[string]svc.Service, len(g.Services))
+	for _, service := range g.Services {
+		services[service.Name] = service
+	}
+	return services
+}
+
+func compareServices(old, new svc.Service) ServiceDiff {
+	d := ServiceDiff{Name: new.Name}
+	d.compareField("type", old.Type.String(), new.Type.String())
+	d.compareField("kind", old.Kind.String(), new.Kind.String())
+	oldBackend, newBackend := backendFields(old), backendFields(new)
+	for _, field := range backendFieldNames {
+		d.compareField(field, oldBackend[field], newBackend[field])
+	}
+	d.compareField("namespace", old.EffectiveNamespace(), new.EffectiveNamespace())
+	d.compareField("cluster", clusterToString(old.Cluster), clusterToString(new.Cluster))
+	d.compareField("numReplicas",
+		strconv.Itoa(int(old.NumReplicas)), strconv.Itoa(int(new.NumReplicas)))
+	d.compareField("isEntrypoint",
+		strconv.FormatBool(old.IsEntrypoint), strconv.FormatBool(new.IsEntrypoint))
+	d.compareField("errorRate", old.ErrorRate.String(), new.ErrorRate.String())
+	d.compareField("responseSize", old.ResponseSize.String(), new.ResponseSize.String())
+	d.compareField("numRbacPolicies",
+		strconv.Itoa(int(old.NumRbacPolicies)), strconv.Itoa(int(new.NumRbacPolicies)))
+	d.compareField("maxConcurrency",
+		strconv.Itoa(int(old.MaxConcurrency)), strconv.Itoa(int(new.MaxConcurrency)))
+	d.compareField("queueSize", strconv.Itoa(int(old.QueueSize)), strconv.Itoa(int(new.QueueSize)))
+	d.compareField("schedule", scheduleToString(old), scheduleToString(new))
+	d.compareField("versions", versionsToString(old), versionsToString(new))
+	d.compareScripts(old.Script, new.Script)
+
+	for _, newVersion := range new.Versions {
+		for _, oldVersion := range old.Versions {
+			if oldVersion.Name != newVersion.Name {
+				continue
+			}
+			if versionDiff := compareVersions(oldVersion, newVersion); !versionDiff.isEmpty() {
+				d.Versions = append(d.Versions, versionDiff)
+			}
+		}
+	}
+	return d
+}
+
+// compareVersions returns the changes to a version of a service, which
+// replaces the settings and script of the service.
+func compareVersions(old, new svc.Version) ServiceDiff {
+	d := ServiceDiff{Name: new.Name}
+	d.compareField("numReplicas",
+		strconv.Itoa(int(old.NumReplicas)), strconv.Itoa(int(new.NumReplicas)))
+	d.compareField("errorRate", old.ErrorRate.String(), new.ErrorRate.String())
+	d.compareField("responseSize", old.ResponseSize.String(), new.ResponseSize.String())
+	d.compareScripts(old.Script, new.Script)
+	return d
+}
+
+// compareField adds the change of the field from oldValue to newValue, if
+// they differ.
+func (d *ServiceDiff) compareField(field, oldValue, newValue string) {
+	if oldValue != newValue {
+		d.Fields = append(d.Fields, FieldChange{field, oldValue, newValue})
+	}
+}
+
+// compareScripts adds the changes to the calls and sleeps from the old to the
+// new script.
+func (d *ServiceDiff) compareScripts(old, new script.Script) {
+	oldCalls, oldSleeps := flattenScript(old)
+	newCalls, newSleeps := flattenScript(new)
+	d.Calls = compareCalls(oldCalls, newCalls)
+	d.Sleeps = compareSleeps(oldSleeps, newSleeps)
+}
+
+func (d ServiceDiff) isEmpty() bool {
+	return len(d.Fields) == 0 && len(d.Calls) == 0 && len(d.Sleeps) == 0 && len(d.Versions) == 0
+}
+
+// backendFieldNames are the settings of the backends services emulate, in the
+// order they are compared.
+var backendFieldNames = []string{
+	"cache.hitRatio",
+	"cache.hitLatency",
+	"database.maxConnections",
+	"database.connectionTimeout",
+	"database.queryLatency.median",
+	"database.queryLatency.p99",
+	"queue.capacity",
+	"queue.workers",
+}
+
+// backendFields formats the settings of the backend of a service by field,
+// the unset ones as their zero value.
+func backendFields(service svc.Service) map[string]string {
+	var cache svc.Cache
+	if service.Cache != nil {
+		cache = *service.Cache
+	}
+	var db svc.Database
+	if service.Database != nil {
+		db = *service.Database
+	}
+	var queue svc.Queue
+	if service.Queue != nil {
+		queue = *service.Queue
+	}
+	return map[string]string{
+		"cache.hitRatio":               cache.HitRatio.String(),
+		"cache.hitLatency":             cache.HitLatency.String(),
+		"database.maxConnections":      strconv.Itoa(int(db.MaxConnections)),
+		"database.connectionTimeout":   db.ConnectionTimeout.String(),
+		"database.queryLatency.median": db.QueryLatency.Median.String(),
+		"database.queryLatency.p99":    db.QueryLatency.P99.String(),
+		"queue.capacity":               strconv.Itoa(int(queue.Capacity)),
+		"queue.workers":                strconv.Itoa(int(queue.Workers)),
+	}
+}
+
+// scheduleToString summarizes the phases of the schedule of a service, e.g.
+// "5m0s-7m0s errorRate=50.00%".
+func scheduleToString(service svc.Service) string {
+	if len(service.Schedule) == 0 {
+		return "none"
+	}
+	phases := make([]string, 0, len(service.Schedule))
+	for _, phase := range service.Schedule {
+		p := fmt.Sprintf("from %s", phase.From)
+		if phase.To != 0 {
+			p = fmt.Sprintf("%s-%s", phase.From, phase.To)
+		}
+		if phase.ErrorRate != nil {
+			p += fmt.Sprintf(" errorRate=%s", phase.ErrorRate)
+		}
+		if phase.ResponseSize != nil {
+			p += fmt.Sprintf(" responseSize=%s", phase.ResponseSize)
+		}
+		if phase.ExtraSleep != 0 {
+			p += fmt.Sprintf(" extraSleep=%s", phase.ExtraSleep)
+		}
+		phases = append(phases, p)
+	}
+	return strings.Join(phases, ", ")
+}
+
+// versionsToString summarizes the versions of a service and their traffic
+// weights, e.g. "v1 (90%), v2 (10%)".
+func versionsToString(service svc.Service) string {
+	if len(service.Versions) == 0 {
+		return "none"
+	}
+	weights := service.VersionWeights()
+	versions := make([]string, 0, len(service.Versions))
+	for i, version := range service.Versions {
+		versions = append(versions, fmt.Sprintf("%s (%d%%)", version.Name, weights[i]))
+	}
+	return strings.Join(versions, ", ")
+}
+
+type namedCall struct {
+	service string
+	call    Call
+}
+
+// flattenScript lists the calls and sleeps of a script in order.
+func flattenScript(s script.Script) (calls []namedCall, sleeps []Sleep) {
+	visit := func(cmd script.Command, step int, concurrent bool) {
+		switch cmd := cmd.(type) {
+		case script.RequestCommand:
+			calls = append(calls, namedCall{cmd.ServiceName, Call{
+				Step:          step,
+				Concurrent:    concurrent,
+				Size:          cmd.Size.String(),
+				Probability:   cmd.ProbabilityString(),
+				NewConnection: cmd.NewConnection,
+			}})
+		case script.SleepCommand:
+			sleeps = append(sleeps, Sleep{
+				Step:       step,
+				Concurrent: concurrent,
+				Duration:   cmd.String(),
+			})
+		}
+	}
+	for step, cmd := range s {
+		if concurrentCmd, ok := cmd.(script.ConcurrentCommand); ok {
+			for _, subCmd := range concurrentCmd {
+				visit(subCmd, step, true)
+			}
+		} else {
+			visit(cmd, step, false)
+		}
+	}
+	return
+}
+
+// compareCalls matches the n-th call to a service in old with the n-th call
+// to the same service in new.
+func compareCalls(old, new []namedCall) (changes []CallChange) {
+	oldByService := map[string][]Call{}
+	for _, c := range old {
+		oldByService[c.service] = append(oldByService[c.service], c.call)
+	}
+	newCount := map[string]int{}
+	for _, c := range new {
+		c := c
+		i := newCount[c.service]
+		newCount[c.service]++
+		if i >= len(oldByService[c.service]) {
+			changes = append(changes, CallChange{Change: Added, Service: c.service, New: &c.call})
+			continue
+		}
+		oldCall := oldByService[c.service][i]
+		if oldCall != c.call {
+			changes = append(changes, CallChange{
+				Change: Modified, Service: c.service, Old: &oldCall, New: &c.call})
+		}
+	}
+	oldCount := map[string]int{}
+	for _, c := range old {
+		c := c
+		i := oldCount[c.service]
+		oldCount[c.service]++
+		if i >= newCount[c.service] {
+			changes = append(changes, CallChange{Change: Removed, Service: c.service, Old: &c.call})
+		}
+	}
+	return
+}
+
+func compareSleeps(old, new []Sleep) (changes []SleepChange) {
+	for i := range new {
+		newSleep := new[i]
+		if i >= len(old) {
+			changes = append(changes, SleepChange{Change: Added, Index: i, New: &newSleep})
+			continue
+		}
+		oldSleep := old[i]
+		if oldSleep != newSleep {
+			changes = append(changes, SleepChange{
+				Change: Modified, Index: i, Old: &oldSleep, New: &newSleep})
+		}
+	}
+	for i := len(new); i < len(old); i++ {
+		oldSleep := old[i]
+		changes = append(changes, SleepChange{Change: Removed, Index: i, Old: &oldSleep})
+	}
+	return
+}
+
+// clusterToString formats the cluster of a service, which may be unnamed.
+func clusterToString(cluster string) string {
+	if cluster == "" {
+		return "none"
+	}
+	return cluster
+}
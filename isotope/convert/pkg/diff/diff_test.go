@@ -0,0 +1,277 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/duration"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svckind"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
+)
+
+func TestCompare(t *testing.T) {
+	old := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", Type: svctype.ServiceHTTP, NumReplicas: 1},
+		{Name: "b", Type: svctype.ServiceHTTP, NumReplicas: 1},
+		{Name: "c", Type: svctype.ServiceHTTP, NumReplicas: 1, Script: script.Script{
+			script.RequestCommand{ServiceName: "a", Size: 1024},
+			script.SleepCommand(10 * time.Millisecond),
+			script.RequestCommand{ServiceName: "b"},
+			script.RequestCommand{ServiceName: "a"},
+		}},
+	}}
+	new := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", Type: svctype.ServiceHTTP, NumReplicas: 1},
+		{Name: "c", Type: svctype.ServiceGRPC, NumReplicas: 2, ErrorRate: 0.1, Schedule: []svc.Phase{
+			{From: duration.Duration(time.Minute), ExtraSleep: duration.Duration(time.Second)},
+		}, Versions: []svc.Version{
+			{Name: "v1"}, {Name: "v2"},
+		}, Script: script.Script{
+			script.ConcurrentCommand{
+				script.RequestCommand{ServiceName: "a", Size: 1024},
+				script.RequestCommand{ServiceName: "d", Probability: 50},
+			},
+			script.SleepCommand(20 * time.Millisecond),
+			script.SleepCommand(time.Millisecond),
+			script.RequestCommand{ServiceName: "a", NewConnection: true},
+		}},
+		{Name: "d", Type: svctype.ServiceHTTP, NumReplicas: 1},
+	}}
+
+	expected := Diff{
+		AddedServices:   []string{"d"},
+		RemovedServices: []string{"b"},
+		ChangedServices: []ServiceDiff{
+			{
+				Name: "c",
+				Fields: []FieldChange{
+					{"type", "HTTP", "gRPC"},
+					{"numReplicas", "1", "2"},
+					{"errorRate", "0.00%", "10.00%"},
+					{"schedule", "none", "from 1m0s extraSleep=1s"},
+					{"versions", "none", "v1 (50%), v2 (50%)"},
+				},
+				Calls: []CallChange{
+					{
+						Change:  Modified,
+						Service: "a",
+						Old:     &Call{Step: 0, Size: "1KiB", Probability: "100%"},
+						New:     &Call{Step: 0, Concurrent: true, Size: "1KiB", Probability: "100%"},
+					},
+					{
+						Change:  Added,
+						Service: "d",
+						New:     &Call{Step: 0, Concurrent: true, Size: "0B", Probability: "50%"},
+					},
+					{
+						Change:  Modified,
+						Service: "a",
+						Old:     &Call{Step: 3, Size: "0B", Probability: "100%"},
+						New:     &Call{Step: 3, Size: "0B", Probability: "100%", NewConnection: true},
+					},
+					{
+						Change:  Removed,
+						Service: "b",
+						Old:     &Call{Step: 2, Size: "0B", Probability: "100%"},
+					},
+				},
+				Sleeps: []SleepChange{
+					{
+						Change: Modified,
+						Index:  0,
+						Old:    &Sleep{Step: 1, Duration: "10ms"},
+						New:    &Sleep{Step: 1, Duration: "20ms"},
+					},
+					{
+						Change: Added,
+						Index:  1,
+						New:    &Sleep{Step: 2, Duration: "1ms"},
+					},
+				},
+			},
+		},
+	}
+
+	actual := Compare(old, new)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v; actual %+v", expected, actual)
+	}
+
+	expectedText := `- service b
++ service d
+~ service c
+    type: HTTP -> gRPC
+    numReplicas: 1 -> 2
+    errorRate: 0.00% -> 10.00%
+    schedule: none -> from 1m0s extraSleep=1s
+    versions: none -> v1 (50%), v2 (50%)
+    ~ call a: step 0, 1KiB, 100% -> step 0 (concurrent), 1KiB, 100%
+    + call d: step 0 (concurrent), 0B, 50%
+    ~ call a: step 3, 0B, 100% -> step 3, 0B, 100%, new connection
+    - call b: step 2, 0B, 100%
+    ~ sleep #0: step 1, 10ms -> step 1, 20ms
+    + sleep #1: step 2, 1ms
+`
+	if actualText := actual.String(); expectedText != actualText {
+		t.Errorf("expected %v; actual %v", expectedText, actualText)
+	}
+}
+
+func TestCompare_Equal(t *testing.T) {
+	g := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", Script: script.Script{script.SleepCommand(time.Second)}},
+	}}
+	d := Compare(g, g)
+	if !d.IsEmpty() {
+		t.Errorf("expected no differences; actual %+v", d)
+	}
+	if d.String() != "no differences\n" {
+		t.Errorf("unexpected text %q", d.String())
+	}
+}
+
+func TestCompare_Fields(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      svc.Service
+		new      svc.Service
+		expected []FieldChange
+	}{
+		{
+			name:     "maxConcurrency",
+			old:      svc.Service{Name: "a"},
+			new:      svc.Service{Name: "a", MaxConcurrency: 10},
+			expected: []FieldChange{{"maxConcurrency", "0", "10"}},
+		},
+		{
+			name:     "queueSize",
+			old:      svc.Service{Name: "a", MaxConcurrency: 10, QueueSize: 5},
+			new:      svc.Service{Name: "a", MaxConcurrency: 10, QueueSize: 20},
+			expected: []FieldChange{{"queueSize", "5", "20"}},
+		},
+		{
+			name: "cache",
+			old: svc.Service{Name: "a", Kind: svckind.KindCache, Cache: &svc.Cache{
+				HitRatio: 0.5, HitLatency: duration.Duration(time.Millisecond)}},
+			new: svc.Service{Name: "a", Kind: svckind.KindCache, Cache: &svc.Cache{
+				HitRatio: 0.9, HitLatency: duration.Duration(2 * time.Millisecond)}},
+			expected: []FieldChange{
+				{"cache.hitRatio", "50.00%", "90.00%"},
+				{"cache.hitLatency", "1ms", "2ms"},
+			},
+		},
+		{
+			name: "database",
+			old: svc.Service{Name: "a", Kind: svckind.KindDatabase, Database: &svc.Database{
+				QueryLatency: svc.Latency{Median: duration.Duration(time.Millisecond)}}},
+			new: svc.Service{Name: "a", Kind: svckind.KindDatabase, Database: &svc.Database{
+				MaxConnections:    10,
+				ConnectionTimeout: duration.Duration(time.Second),
+				QueryLatency: svc.Latency{
+					Median: duration.Duration(time.Millisecond),
+					P99:    duration.Duration(10 * time.Millisecond),
+				},
+			}},
+			expected: []FieldChange{
+				{"database.maxConnections", "0", "10"},
+				{"database.connectionTimeout", "0s", "1s"},
+				{"database.queryLatency.p99", "0s", "10ms"},
+			},
+		},
+		{
+			name:     "queue",
+			old:      svc.Service{Name: "a", Kind: svckind.KindQueue, Queue: &svc.Queue{Capacity: 100}},
+			new:      svc.Service{Name: "a", Kind: svckind.KindQueue, Queue: &svc.Queue{Capacity: 100, Workers: 4}},
+			expected: []FieldChange{{"queue.workers", "0", "4"}},
+		},
+		{
+			name: "kind",
+			old:  svc.Service{Name: "a"},
+			new:  svc.Service{Name: "a", Kind: svckind.KindQueue, Queue: &svc.Queue{Workers: 4}},
+			expected: []FieldChange{
+				{"kind", "service", "queue"},
+				{"queue.workers", "0", "4"},
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			d := Compare(graph.ServiceGraph{Services: []svc.Service{test.old}},
+				graph.ServiceGraph{Services: []svc.Service{test.new}})
+			if len(d.ChangedServices) != 1 {
+				t.Fatalf("expected a changed service; actual %+v", d)
+			}
+			if actual := d.ChangedServices[0].Fields; !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("expected %+v; actual %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompare_Versions(t *testing.T) {
+	old := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", Versions: []svc.Version{
+			{Name: "v1", NumReplicas: 1},
+			{Name: "v2", NumReplicas: 1, Script: script.Script{
+				script.RequestCommand{ServiceName: "b"},
+			}},
+		}},
+	}}
+	new := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", Versions: []svc.Version{
+			{Name: "v1", NumReplicas: 1},
+			{Name: "v2", NumReplicas: 3, ErrorRate: 0.5, Script: script.Script{
+				script.RequestCommand{ServiceName: "b"},
+				script.SleepCommand(time.Millisecond),
+			}},
+		}},
+	}}
+
+	expected := Diff{ChangedServices: []ServiceDiff{{
+		Name: "a",
+		Versions: []ServiceDiff{{
+			Name: "v2",
+			Fields: []FieldChange{
+				{"numReplicas", "1", "3"},
+				{"errorRate", "0.00%", "50.00%"},
+			},
+			Sleeps: []SleepChange{
+				{Change: Added, Index: 0, New: &Sleep{Step: 1, Duration: "1ms"}},
+			},
+		}},
+	}}}
+	actual := Compare(old, new)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v; actual %+v", expected, actual)
+	}
+
+	expectedText := `~ service a
+    ~ version v2
+        numReplicas: 1 -> 3
+        errorRate: 0.00% -> 50.00%
+        + sleep #0: step 1, 1ms
+`
+	if actualText := actual.String(); expectedText != actualText {
+		t.Errorf("expected %v; actual %v", expectedText, actualText)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+var changeSymbols = map[Change]string{
+	Added:    "+",
+	Removed:  "-",
+	Modified: "~",
+}
+
+// String formats the diff for humans, one change per line, prefixing added
+// items with "+", removed ones with "-" and modified ones with "~".
+func (d Diff) String() string {
+	if d.IsEmpty() {
+		return "no differences\n"
+	}
+
+	var b strings.Builder
+	for _, name := range d.RemovedServices {
+		fmt.Fprintf(&b, "- service %s\n", name)
+	}
+	for _, name := range d.AddedServices {
+		fmt.Fprintf(&b, "+ service %s\n", name)
+	}
+	for _, service := range d.ChangedServices {
+		fmt.Fprintf(&b, "~ service %s\n", service.Name)
+		writeServiceDiff(&b, service, "    ")
+	}
+	return b.String()
+}
+
+// writeServiceDiff writes the changes of a service or a version, indented by
+// indent, and the changes of its versions further indented.
+func writeServiceDiff(b *strings.Builder, d ServiceDiff, indent string) {
+	for _, field := range d.Fields {
+		fmt.Fprintf(b, "%s%s: %s -> %s\n", indent, field.Field, field.Old, field.New)
+	}
+	for _, call := range d.Calls {
+		fmt.Fprintf(b, "%s%s call %s: %s\n", indent,
+			changeSymbols[call.Change], call.Service,
+			oldToNew(callToString(call.Old), callToString(call.New)))
+	}
+	for _, sleep := range d.Sleeps {
+		fmt.Fprintf(b, "%s%s sleep #%d: %s\n", indent,
+			changeSymbols[sleep.Change], sleep.Index,
+			oldToNew(sleepToString(sleep.Old), sleepToString(sleep.New)))
+	}
+	for _, version := range d.Versions {
+		fmt.Fprintf(b, "%s~ version %s\n", indent, version.Name)
+		writeServiceDiff(b, version, indent+"    ")
+	}
+}
+
+// oldToNew formats whichever of old and new are not empty.
+func oldToNew(old, new string) string {
+	switch {
+	case old == "":
+		return new
+	case new == "":
+		return old
+	default:
+		return fmt.Sprintf("%s -> %s", old, new)
+	}
+}
+
+func callToString(c *Call) string {
+	if c == nil {
+		return ""
+	}
+	s := fmt.Sprintf("%s, %s, %s", step(c.Step, c.Concurrent), c.Size, c.Probability)
+	if c.NewConnection {
+		s += ", new connection"
+	}
+	return s
+}
+
+func sleepToString(s *Sleep) string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s, %s", step(s.Step, s.Concurrent), s.Duration)
+}
+
+func step(index int, concurrent bool) string {
+	if concurrent {
+		return fmt.Sprintf("step %d (concurrent)", index)
+	}
+	return fmt.Sprintf("step %d", index)
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duration
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input    []byte
+		duration Duration
+		isErr    bool
+	}{
+		{[]byte(`"0s"`), 0, false},
+		{[]byte(`"200ms"`), Duration(200 * time.Millisecond), false},
+		{[]byte(`"1m30s"`), Duration(90 * time.Second), false},
+		{[]byte(`"1 minute"`), 0, true},
+		{[]byte(`60`), 0, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			var duration Duration
+			err := json.Unmarshal(test.input, &duration)
+			if test.isErr != (err != nil) {
+				t.Errorf("expected error %v; actual %v", test.isErr, err)
+			}
+			if test.duration != duration {
+				t.Errorf("expected %v; actual %v", test.duration, duration)
+			}
+		})
+	}
+}
+
+func TestDuration_MarshalJSON(t *testing.T) {
+	output, err := json.Marshal(Duration(90 * time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `"1m30s"`; expected != string(output) {
+		t.Errorf("expected %s; actual %s", expected, output)
+	}
+}
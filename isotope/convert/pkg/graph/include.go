@@ -0,0 +1,149 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+)
+
+const includeKey = "include"
+
+// ErrIncludeWithoutFile is returned when a service graph with includes is not
+// read with FromYAMLFile, so that included paths cannot be resolved.
+var ErrIncludeWithoutFile = errors.New(
+	"includes are only supported when reading a service graph from a file")
+
+// FromYAMLFile reads the service graph at path, with the topology files it
+// includes.
+//
+// The "include" list holds paths, relative to the including file, of other
+// topology files. Their services are prepended to the file's services, and
+// their templates and defaults are added to the file's, unless the file
+// already defines them.
+func FromYAMLFile(path string) (sg ServiceGraph, err error) {
+	doc, err := readYAMLDocument(path, map[string]bool{})
+	if err != nil {
+		return
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &sg)
+	return
+}
+
+// readYAMLDocument returns the YAML document at path as JSON-compatible
+// values, with its includes merged in. reading holds the absolute paths of
+// the files being read, to detect cycles.
+func readYAMLDocument(
+	path string, reading map[string]bool) (map[string]interface{}, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if reading[absPath] {
+		return nil, fmt.Errorf("%s includes itself", path)
+	}
+	reading[absPath] = true
+	defer delete(reading, absPath)
+
+	yamlContents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	jsonContents, err := yaml.YAMLToJSON(yamlContents)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(jsonContents, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	includes, ok := doc[includeKey]
+	if !ok {
+		return doc, nil
+	}
+	delete(doc, includeKey)
+	includePaths, ok := includes.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: %q must be a list of paths", path, includeKey)
+	}
+
+	var services []interface{}
+	for _, includePath := range includePaths {
+		includePath, ok := includePath.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: %q must be a list of paths", path, includeKey)
+		}
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(path), includePath)
+		}
+		included, err := readYAMLDocument(includePath, reading)
+		if err != nil {
+			return nil, err
+		}
+
+		if includedServices, ok := included[servicesKey].([]interface{}); ok {
+			services = append(services, includedServices...)
+		}
+		for _, key := range []string{templatesKey, defaultsKey} {
+			if err := mergeMissingKeys(doc, included, key); err != nil {
+				return nil, fmt.Errorf("%s: %v", includePath, err)
+			}
+		}
+	}
+	if ownServices, ok := doc[servicesKey].([]interface{}); ok {
+		services = append(services, ownServices...)
+	}
+	if services != nil {
+		doc[servicesKey] = services
+	}
+	return doc, nil
+}
+
+// mergeMissingKeys adds the entries of the map at key in src which are
+// missing from the map at key in dst.
+func mergeMissingKeys(dst, src map[string]interface{}, key string) error {
+	srcValue, ok := src[key]
+	if !ok {
+		return nil
+	}
+	srcMap, ok := srcValue.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%q must be a map", key)
+	}
+	dstMap, ok := dst[key].(map[string]interface{})
+	if !ok {
+		dstMap = map[string]interface{}{}
+		dst[key] = dstMap
+	}
+	for k, v := range srcMap {
+		if _, ok := dstMap[k]; !ok {
+			dstMap[k] = v
+		}
+	}
+	return nil
+}
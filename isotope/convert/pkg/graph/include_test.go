@@ -0,0 +1,71 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
+)
+
+func TestFromYAMLFile(t *testing.T) {
+	expected := ServiceGraph{[]svc.Service{
+		{
+			Name:         "a",
+			Type:         svctype.ServiceHTTP,
+			NumReplicas:  2,
+			ResponseSize: 128,
+			Script:       script.Script{script.SleepCommand(10 * time.Millisecond)},
+		},
+		{
+			Name:         "b",
+			Type:         svctype.ServiceHTTP,
+			NumReplicas:  2,
+			ResponseSize: 128,
+			Script:       script.Script{script.SleepCommand(10 * time.Millisecond)},
+		},
+		{
+			Name:         "frontend",
+			Type:         svctype.ServiceHTTP,
+			NumReplicas:  2,
+			ResponseSize: 128,
+			IsEntrypoint: true,
+			Script: script.Script{
+				script.ConcurrentCommand{
+					script.RequestCommand{ServiceName: "a", Size: 1024},
+					script.RequestCommand{ServiceName: "b", Size: 1024},
+				},
+			},
+		},
+	}}
+
+	graph, err := FromYAMLFile("testdata/include/main.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, graph) {
+		t.Errorf("expected %v; actual %v", expected, graph)
+	}
+}
+
+func TestFromYAMLFile_IncludeCycle(t *testing.T) {
+	if _, err := FromYAMLFile("testdata/include/cycle.yaml"); err == nil {
+		t.Errorf("expected an error for a file including itself")
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"istio.io/tools/isotope/convert/pkg/graph/duration"
+	"istio.io/tools/isotope/convert/pkg/graph/pct"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/size"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svckind"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
+)
+
+// Schema is a JSON Schema (draft-07), limited to the keywords used to
+// describe service graphs.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// definitionRefs maps the Go types which have a custom JSON encoding to the
+// definitions describing them.
+var definitionRefs = map[reflect.Type]string{
+	reflect.TypeOf(svctype.ServiceType(0)): "serviceType",
+	reflect.TypeOf(svckind.ServiceKind(0)): "serviceKind",
+	reflect.TypeOf(pct.Percentage(0)):      "percentage",
+	reflect.TypeOf(size.ByteSize(0)):       "byteSize",
+	reflect.TypeOf(script.Script{}):        "script",
+	reflect.TypeOf(svc.Version{}):          "version",
+	reflect.TypeOf(svc.Phase{}):            "phase",
+	reflect.TypeOf(duration.Duration(0)):   "duration",
+}
+
+// JSONSchema returns the JSON Schema of service graph files, as indented
+// JSON. The properties of services, defaults and requests are derived from
+// their Go types, so the schema follows them.
+func JSONSchema() ([]byte, error) {
+	schema, err := serviceGraphSchema()
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func serviceGraphSchema() (*Schema, error) {
+	serviceProperties, err := propertiesOf(reflect.TypeOf(svc.Service{}))
+	if err != nil {
+		return nil, err
+	}
+	serviceProperties[extendsKey] = ref("templateReference")
+	serviceProperties["maxConcurrency"].Minimum = float(0)
+	serviceProperties["queueSize"].Minimum = float(0)
+
+	versionProperties, err := propertiesOf(reflect.TypeOf(svc.Version{}))
+	if err != nil {
+		return nil, err
+	}
+	versionProperties["weight"].Minimum = float(0)
+	versionProperties["weight"].Maximum = float(100)
+
+	phaseProperties, err := propertiesOf(reflect.TypeOf(svc.Phase{}))
+	if err != nil {
+		return nil, err
+	}
+
+	defaultsProperties, err := propertiesOf(reflect.TypeOf(defaults{}))
+	if err != nil {
+		return nil, err
+	}
+	defaultsProperties["maxConcurrency"].Minimum = float(0)
+	defaultsProperties["queueSize"].Minimum = float(0)
+
+	requestProperties, err := propertiesOf(reflect.TypeOf(script.RequestCommand{}))
+	if err != nil {
+		return nil, err
+	}
+	requestProperties["probability"].Minimum = float(0)
+	requestProperties["probability"].Maximum = float(100)
+
+	return &Schema{
+		Schema:      schemaDraft,
+		Title:       "Isotope service graph",
+		Description: "A service graph which mocks a service-oriented architecture.",
+		Type:        "object",
+		Properties: map[string]*Schema{
+			"apiVersion": {Type: "string"},
+			"kind":       {Type: "string"},
+			includeKey: {
+				Description: "Paths of other service graph files, relative to this one.",
+				Type:        "array",
+				Items:       &Schema{Type: "string"},
+			},
+			templatesKey: {
+				Description:          "Named partial services and script fragments.",
+				Type:                 "object",
+				AdditionalProperties: &Schema{OneOf: []*Schema{ref("serviceTemplate"), ref("script")}},
+			},
+			defaultsKey: {
+				Description:          "Settings of services and requests which omit them.",
+				Type:                 "object",
+				Properties:           defaultsProperties,
+				AdditionalProperties: false,
+			},
+			servicesKey: {
+				Type:  "array",
+				Items: ref("service"),
+			},
+		},
+		AdditionalProperties: false,
+		Definitions: map[string]*Schema{
+			"service": {
+				Type:                 "object",
+				Properties:           serviceProperties,
+				Required:             []string{"name"},
+				AdditionalProperties: false,
+			},
+			"serviceTemplate": {
+				Type:                 "object",
+				Properties:           serviceProperties,
+				AdditionalProperties: false,
+			},
+			"version": {
+				Type:                 "object",
+				Properties:           versionProperties,
+				Required:             []string{"name"},
+				AdditionalProperties: false,
+			},
+			"phase": {
+				Description:          "A change of the behaviour of a service over a period of time after its start.",
+				Type:                 "object",
+				Properties:           phaseProperties,
+				Required:             []string{"from"},
+				AdditionalProperties: false,
+			},
+			"templateReference": {
+				OneOf: []*Schema{
+					{Type: "string"},
+					{
+						Type: "object",
+						Properties: map[string]*Schema{
+							templateNameKey: {Type: "string"},
+							templateParamsKey: {
+								Type:                 "object",
+								AdditionalProperties: true,
+							},
+						},
+						Required:             []string{templateNameKey},
+						AdditionalProperties: false,
+					},
+				},
+			},
+			"script": {
+				Description: "Steps run sequentially when the service is called.",
+				Type:        "array",
+				Items: &Schema{OneOf: []*Schema{
+					ref("command"),
+					{
+						Description: "Commands run concurrently.",
+						Type:        "array",
+						Items:       ref("command"),
+					},
+				}},
+			},
+			"command": {
+				OneOf: []*Schema{
+					singleKeyObject("sleep", ref("duration")),
+					singleKeyObject("call", &Schema{OneOf: []*Schema{
+						{Description: "Name of the called service.", Type: "string"},
+						{
+							Type:                 "object",
+							Properties:           requestProperties,
+							Required:             []string{"service"},
+							AdditionalProperties: false,
+						},
+					}}),
+					singleKeyObject(useKey, ref("templateReference")),
+				},
+			},
+			"duration": {
+				Description: "A Go duration, e.g. 10ms or 1m30s.",
+				OneOf: []*Schema{
+					{Type: "string", Pattern: `^([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`},
+					ref("parameter"),
+				},
+			},
+			"byteSize": {
+				Description: "A number of bytes, e.g. 128 or 1KB.",
+				OneOf: []*Schema{
+					{Type: "integer", Minimum: float(0)},
+					{Type: "string", Pattern: `^[0-9]+(\.[0-9]+)* ?[kKmMgGtTpP]?[iI]?[bB]?$`},
+					ref("parameter"),
+				},
+			},
+			"percentage": {
+				Description: "A number between 0 and 1, or a string between 0% and 100%.",
+				OneOf: []*Schema{
+					{Type: "number", Minimum: float(0), Maximum: float(1)},
+					{Type: "string", Pattern: `^[0-9]+(\.[0-9]*)?%$`},
+					ref("parameter"),
+				},
+			},
+			"serviceType": {
+				OneOf: []*Schema{
+					{Enum: []string{"http", "grpc", "tcp"}},
+					ref("parameter"),
+				},
+			},
+			"serviceKind": {
+				OneOf: []*Schema{
+					{Enum: []string{"service", "cache", "database", "queue"}},
+					ref("parameter"),
+				},
+			},
+			"parameter": {
+				Description: "A template parameter, replaced when the template is used.",
+				Type:        "string",
+				Pattern:     "^" + paramRegexp.String() + "$",
+			},
+		},
+	}, nil
+}
+
+// propertiesOf returns the schemas of the JSON properties of the struct t.
+func propertiesOf(t reflect.Type) (map[string]*Schema, error) {
+	properties := make(map[string]*Schema, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema, err := schemaOf(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", t.Name(), field.Name, err)
+		}
+		properties[name] = schema
+	}
+	return properties, nil
+}
+
+func schemaOf(t reflect.Type) (*Schema, error) {
+	if definition, ok := definitionRefs[t]; ok {
+		return ref(definition), nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.Slice:
+		items, err := schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Struct:
+		properties, err := propertiesOf(t)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{
+			Type:                 "object",
+			Properties:           properties,
+			AdditionalProperties: false,
+		}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	default:
+		return nil, fmt.Errorf("no JSON Schema for type %s", t)
+	}
+}
+
+func ref(definition string) *Schema {
+	return &Schema{Ref: "#/definitions/" + definition}
+}
+
+func singleKeyObject(key string, value *Schema) *Schema {
+	return &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{key: value},
+		Required:             []string{key},
+		AdditionalProperties: false,
+	}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+var update = flag.Bool("update", false, "update the published JSON Schema")
+
+const schemaPath = "../../../topology.schema.json"
+
+func TestJSONSchema(t *testing.T) {
+	actual, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if err := ioutil.WriteFile(schemaPath, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("%s is out of date; run go test ./pkg/graph -update", schemaPath)
+	}
+}
+
+// TestJSONSchema_Properties checks the schema describes every field of the
+// Go types, by walking their JSON struct tags.
+func TestJSONSchema_Properties(t *testing.T) {
+	schema, err := serviceGraphSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := schema.Definitions["command"]
+	request := command.OneOf[1].Properties["call"].OneOf[1]
+	service := schema.Definitions["service"]
+
+	tests := []struct {
+		name       string
+		typ        reflect.Type
+		properties map[string]*Schema
+		extra      []string
+	}{
+		{
+			"service",
+			reflect.TypeOf(svc.Service{}),
+			service.Properties,
+			[]string{extendsKey},
+		},
+		{
+			"cache",
+			reflect.TypeOf(svc.Cache{}),
+			service.Properties["cache"].Properties,
+			nil,
+		},
+		{
+			"database",
+			reflect.TypeOf(svc.Database{}),
+			service.Properties["database"].Properties,
+			nil,
+		},
+		{
+			"queue",
+			reflect.TypeOf(svc.Queue{}),
+			service.Properties["queue"].Properties,
+			nil,
+		},
+		{
+			"version",
+			reflect.TypeOf(svc.Version{}),
+			schema.Definitions["version"].Properties,
+			nil,
+		},
+		{
+			"phase",
+			reflect.TypeOf(svc.Phase{}),
+			schema.Definitions["phase"].Properties,
+			nil,
+		},
+		{
+			"request",
+			reflect.TypeOf(script.RequestCommand{}),
+			request.Properties,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			expected := append(jsonFieldNames(test.typ), test.extra...)
+			actual := make([]string, 0, len(test.properties))
+			for k := range test.properties {
+				actual = append(actual, k)
+			}
+			sort.Strings(expected)
+			sort.Strings(actual)
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected %v; actual %v", expected, actual)
+			}
+		})
+	}
+}
+
+// jsonFieldNames returns the names encoding/json uses for the exported fields
+// of the struct t.
+func jsonFieldNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -17,12 +17,13 @@ package script
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"istio.io/tools/isotope/convert/pkg/graph/size"
 )
 
-// RequestCommand describes a command to send an HTTP request to another
-// service.
+// RequestCommand describes a command to send a request to another service,
+// over HTTP or, for TCP services, their length-prefixed protocol.
 type RequestCommand struct {
 	ServiceName string `json:"service"`
 	// Size is the number of bytes in the request body.
@@ -30,6 +31,19 @@ type RequestCommand struct {
 	// Probability is the chance a call will be made, from 1-100%. If unset, the call will always be made
 	// 1 means 1% of calls will be made; 100 means 100% of calls will be made
 	Probability int `json:"probability,omitempty"`
+	// NewConnection opens a connection for the request and closes it
+	// afterwards, instead of reusing a pooled connection.
+	NewConnection bool `json:"newConnection,omitempty"`
+}
+
+// ProbabilityString formats the chance the request is sent as a percentage,
+// where an unset Probability means it is always sent.
+func (c RequestCommand) ProbabilityString() string {
+	probability := c.Probability
+	if probability == 0 {
+		probability = 100
+	}
+	return fmt.Sprintf("%d%%", probability)
 }
 
 var (
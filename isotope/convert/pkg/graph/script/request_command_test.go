@@ -102,3 +102,27 @@ func TestRequestCommand_UnmarshalJSON_Default(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestCommand_ProbabilityString(t *testing.T) {
+	tests := []struct {
+		probability int
+		expected    string
+	}{
+		{0, "100%"},
+		{1, "1%"},
+		{50, "50%"},
+		{100, "100%"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.expected, func(t *testing.T) {
+			t.Parallel()
+
+			command := RequestCommand{ServiceName: "A", Probability: test.probability}
+			if actual := command.ProbabilityString(); test.expected != actual {
+				t.Errorf("expected %v; actual %v", test.expected, actual)
+			}
+		})
+	}
+}
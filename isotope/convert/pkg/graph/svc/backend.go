@@ -0,0 +1,137 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svc
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph/duration"
+	"istio.io/tools/isotope/convert/pkg/graph/pct"
+	"istio.io/tools/isotope/convert/pkg/graph/svckind"
+)
+
+// Cache describes a service of kind cache. Hits are answered after HitLatency;
+// misses fall through to the script of the service.
+type Cache struct {
+	// HitRatio is the percentage chance between 0 and 1 that a request hits the
+	// cache.
+	HitRatio pct.Percentage `json:"hitRatio"`
+
+	// HitLatency is the time it takes to answer a hit.
+	HitLatency duration.Duration `json:"hitLatency,omitempty"`
+}
+
+// Database describes a service of kind database. Each request runs a query,
+// holding a connection of the pool for its duration, before running the
+// script of the service.
+type Database struct {
+	// MaxConnections is the size of the connection pool of each replica. If
+	// unset, the pool is unlimited.
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+
+	// ConnectionTimeout is how long a query waits for a free connection before
+	// the request fails with a 503 Service Unavailable. If unset, queries wait
+	// as long as their request lasts.
+	ConnectionTimeout duration.Duration `json:"connectionTimeout,omitempty"`
+
+	// QueryLatency is the distribution of the duration of queries.
+	QueryLatency Latency `json:"queryLatency"`
+}
+
+// Queue describes a service of kind queue. Requests are accepted right away
+// and their script is run later by a worker.
+type Queue struct {
+	// Capacity is the number of requests each replica holds before rejecting
+	// requests with a 503 Service Unavailable. If unset, it is 10000.
+	Capacity int32 `json:"capacity,omitempty"`
+
+	// Workers is the number of requests each replica processes concurrently.
+	// If unset, requests are processed one at a time.
+	Workers int32 `json:"workers,omitempty"`
+}
+
+// Latency is a log-normal distribution of durations, given by its median and
+// its 99th percentile. If P99 is unset, the duration is always the median.
+type Latency struct {
+	Median duration.Duration `json:"median"`
+	P99    duration.Duration `json:"p99,omitempty"`
+}
+
+// z99 is the 99th percentile of the standard normal distribution.
+var z99 = math.Sqrt2 * math.Erfinv(2*0.99-1)
+
+// Quantile returns the duration below which the fraction q, between 0 and 1,
+// of durations fall. Sampling it at a uniformly random q samples the
+// distribution.
+func (l Latency) Quantile(q float64) time.Duration {
+	median := float64(l.Median)
+	if l.P99 <= l.Median || median <= 0 {
+		return time.Duration(l.Median)
+	}
+	sigma := math.Log(float64(l.P99)/median) / z99
+	z := math.Sqrt2 * math.Erfinv(2*q-1)
+	return time.Duration(median * math.Exp(sigma*z))
+}
+
+// validateBackend returns an error if the settings of the backend the service
+// emulates are invalid or do not match its kind.
+func (svc Service) validateBackend() error {
+	invalid := func(format string, args ...interface{}) error {
+		return ErrInvalidBackend{Service: svc.Name, Reason: fmt.Sprintf(format, args...)}
+	}
+	settings := []struct {
+		kind  svckind.ServiceKind
+		isSet bool
+	}{
+		{svckind.KindCache, svc.Cache != nil},
+		{svckind.KindDatabase, svc.Database != nil},
+		{svckind.KindQueue, svc.Queue != nil},
+	}
+	for _, s := range settings {
+		if s.isSet && s.kind != svc.Kind {
+			return invalid("%s settings on a service of kind %s", s.kind, svc.Kind)
+		}
+	}
+
+	if svc.Cache != nil && svc.Cache.HitLatency < 0 {
+		return invalid("negative cache hit latency")
+	}
+	if db := svc.Database; db != nil {
+		if db.MaxConnections < 0 || db.ConnectionTimeout < 0 {
+			return invalid("negative database connection limits")
+		}
+		if db.QueryLatency.Median < 0 ||
+			(db.QueryLatency.P99 != 0 && db.QueryLatency.P99 < db.QueryLatency.Median) {
+			return invalid("database query latency p99 below its median")
+		}
+	}
+	if q := svc.Queue; q != nil && (q.Capacity < 0 || q.Workers < 0) {
+		return invalid("negative queue capacity or workers")
+	}
+	return nil
+}
+
+// ErrInvalidBackend is returned when the settings of the backend a service
+// emulates are invalid.
+type ErrInvalidBackend struct {
+	Service string
+	Reason  string
+}
+
+func (e ErrInvalidBackend) Error() string {
+	return fmt.Sprintf(`invalid backend of service "%s": %s`, e.Service, e.Reason)
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph/duration"
+)
+
+func TestLatency_Quantile(t *testing.T) {
+	tests := []struct {
+		latency  Latency
+		q        float64
+		expected time.Duration
+	}{
+		{Latency{Median: duration.Duration(time.Millisecond)}, 0.99, time.Millisecond},
+		{Latency{
+			Median: duration.Duration(2 * time.Millisecond),
+			P99:    duration.Duration(50 * time.Millisecond),
+		}, 0.5, 2 * time.Millisecond},
+		{Latency{
+			Median: duration.Duration(2 * time.Millisecond),
+			P99:    duration.Duration(50 * time.Millisecond),
+		}, 0.99, 50 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			actual := test.latency.Quantile(test.q)
+			// Allow for floating point rounding.
+			if diff := actual - test.expected; diff < -time.Microsecond || diff > time.Microsecond {
+				t.Errorf("expected %v; actual %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestService_UnmarshalJSON_Backend(t *testing.T) {
+	tests := []struct {
+		input []byte
+		isErr bool
+	}{
+		{[]byte(`{"name": "a", "kind": "cache", "cache": {"hitRatio": "90%", "hitLatency": "1ms"}}`), false},
+		{[]byte(`{"name": "a", "kind": "database", "database": {"maxConnections": 10, "queryLatency": {"median": "2ms", "p99": "50ms"}}}`), false},
+		{[]byte(`{"name": "a", "kind": "queue", "queue": {"capacity": 100, "workers": 4}}`), false},
+		{[]byte(`{"name": "a", "kind": "queue"}`), false},
+		{[]byte(`{"name": "a", "cache": {"hitRatio": "90%"}}`), true},
+		{[]byte(`{"name": "a", "kind": "database", "database": {"queryLatency": {"median": "50ms", "p99": "2ms"}}}`), true},
+		{[]byte(`{"name": "a", "kind": "queue", "queue": {"workers": -1}}`), true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			var service Service
+			err := json.Unmarshal(test.input, &service)
+			if test.isErr != (err != nil) {
+				t.Errorf("expected error %v; actual %v", test.isErr, err)
+			}
+		})
+	}
+}
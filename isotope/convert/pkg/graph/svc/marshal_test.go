@@ -36,6 +36,17 @@ func TestService_MarshalJSON(t *testing.T) {
 			[]byte(`{"name":"a","type":"http","numReplicas":1,"numRbacPolicies":0}`),
 			nil,
 		},
+		{
+			Service{
+				Name:        "a",
+				Type:        svctype.ServiceHTTP,
+				NumReplicas: 1,
+				Versions:    []Version{{Name: "v1"}},
+			},
+			[]byte(`{"name":"a","type":"http","numReplicas":1,"numRbacPolicies":0,` +
+				`"versions":[{"name":"v1","numReplicas":0,"errorRate":0,"responseSize":"0B","script":[]}]}`),
+			nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,80 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svc
+
+import (
+	"fmt"
+	"regexp"
+
+	"istio.io/tools/isotope/convert/pkg/consts"
+)
+
+// clusterDomain is the DNS domain of the services of each cluster.
+const clusterDomain = "cluster.local"
+
+// dnsLabelRegexp matches the names Kubernetes accepts for namespaces.
+var dnsLabelRegexp = regexp.MustCompile("^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$")
+
+// EffectiveNamespace returns the namespace the service is deployed in,
+// defaulting to the service graph namespace.
+func (svc Service) EffectiveNamespace() string {
+	if svc.Namespace == "" {
+		return consts.ServiceGraphNamespace
+	}
+	return svc.Namespace
+}
+
+// FQDN returns the fully qualified domain name of the service, which
+// resolves from any namespace and, in a multi-cluster mesh, any cluster.
+func (svc Service) FQDN() string {
+	return fmt.Sprintf("%s.%s.svc.%s", svc.Name, svc.EffectiveNamespace(), clusterDomain)
+}
+
+// Host returns the name callers in namespace use to address the service: its
+// bare name within its own namespace, otherwise its FQDN. Bare names resolve
+// on the first lookup, whereas FQDNs without a trailing dot go through the
+// search domains of the pods first (ndots:5). Both match the FQDN hosts of
+// the traffic rules.
+func (svc Service) Host(namespace string) string {
+	if svc.EffectiveNamespace() == namespace {
+		return svc.Name
+	}
+	return svc.FQDN()
+}
+
+// validatePlacement checks the namespace and the cluster of the service are
+// valid DNS labels, as they end up in hostnames and Kubernetes names.
+func (svc Service) validatePlacement() error {
+	if svc.Namespace != "" && !dnsLabelRegexp.MatchString(svc.Namespace) {
+		return ErrInvalidPlacement{Service: svc.Name, Field: "namespace", Value: svc.Namespace}
+	}
+	if svc.Cluster != "" && !dnsLabelRegexp.MatchString(svc.Cluster) {
+		return ErrInvalidPlacement{Service: svc.Name, Field: "cluster", Value: svc.Cluster}
+	}
+	return nil
+}
+
+// ErrInvalidPlacement is returned when the namespace or the cluster of a
+// service is not a valid DNS label.
+type ErrInvalidPlacement struct {
+	Service string
+	Field   string
+	Value   string
+}
+
+func (e ErrInvalidPlacement) Error() string {
+	return fmt.Sprintf(
+		`the %s "%s" of service "%s" must be a lowercase DNS label`, e.Field, e.Value, e.Service)
+}
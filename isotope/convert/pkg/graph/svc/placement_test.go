@@ -0,0 +1,76 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestService_Host(t *testing.T) {
+	tests := []struct {
+		service   Service
+		namespace string
+		host      string
+	}{
+		{Service{Name: "a"}, "service-graph", "a"},
+		{Service{Name: "a"}, "other", "a.service-graph.svc.cluster.local"},
+		{Service{Name: "a", Namespace: "other"}, "other", "a"},
+		{Service{Name: "a", Namespace: "other", Cluster: "west"}, "service-graph",
+			"a.other.svc.cluster.local"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			host := test.service.Host(test.namespace)
+			if test.host != host {
+				t.Errorf("expected %v; actual %v", test.host, host)
+			}
+		})
+	}
+}
+
+func TestService_UnmarshalJSON_Placement(t *testing.T) {
+	tests := []struct {
+		input []byte
+		err   error
+	}{
+		{[]byte(`{"name": "a", "namespace": "shop", "cluster": "west"}`), nil},
+		{
+			[]byte(`{"name": "a", "namespace": "Shop"}`),
+			ErrInvalidPlacement{Service: "a", Field: "namespace", Value: "Shop"},
+		},
+		{
+			[]byte(`{"name": "a", "cluster": "us_west"}`),
+			ErrInvalidPlacement{Service: "a", Field: "cluster", Value: "us_west"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			var service Service
+			err := json.Unmarshal(test.input, &service)
+			if test.err != err {
+				t.Errorf("expected %v; actual %v", test.err, err)
+			}
+		})
+	}
+}
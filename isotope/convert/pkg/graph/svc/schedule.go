@@ -0,0 +1,90 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svc
+
+import (
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph/duration"
+	"istio.io/tools/isotope/convert/pkg/graph/pct"
+	"istio.io/tools/isotope/convert/pkg/graph/size"
+)
+
+// Phase changes the behaviour of a service during a period of time, relative
+// to the start of each replica.
+type Phase struct {
+	// From is when the phase starts.
+	From duration.Duration `json:"from"`
+
+	// To is when the phase ends. If unset, the phase never ends.
+	To duration.Duration `json:"to,omitempty"`
+
+	// ErrorRate replaces the error rate of the service during the phase.
+	ErrorRate *pct.Percentage `json:"errorRate,omitempty"`
+
+	// ResponseSize replaces the response size of the service during the phase.
+	ResponseSize *size.ByteSize `json:"responseSize,omitempty"`
+
+	// ExtraSleep is added to the duration of every request during the phase.
+	ExtraSleep duration.Duration `json:"extraSleep,omitempty"`
+}
+
+// IsActive returns true if the phase applies elapsed after the start.
+func (p Phase) IsActive(elapsed time.Duration) bool {
+	if elapsed < time.Duration(p.From) {
+		return false
+	}
+	return p.To == 0 || elapsed < time.Duration(p.To)
+}
+
+// Behaviour is what a service does when it is called, at a point of its
+// schedule.
+type Behaviour struct {
+	ErrorRate    pct.Percentage
+	ResponseSize size.ByteSize
+	ExtraSleep   time.Duration
+}
+
+// BehaviourAt returns the behaviour of the service elapsed after its start.
+// When phases overlap, the last one wins, except for their extra sleeps which
+// add up.
+func (svc Service) BehaviourAt(elapsed time.Duration) Behaviour {
+	b := Behaviour{ErrorRate: svc.ErrorRate, ResponseSize: svc.ResponseSize}
+	for _, phase := range svc.Schedule {
+		if !phase.IsActive(elapsed) {
+			continue
+		}
+		if phase.ErrorRate != nil {
+			b.ErrorRate = *phase.ErrorRate
+		}
+		if phase.ResponseSize != nil {
+			b.ResponseSize = *phase.ResponseSize
+		}
+		b.ExtraSleep += time.Duration(phase.ExtraSleep)
+	}
+	return b
+}
+
+// MaxResponseSize returns the largest response size of the service throughout
+// its schedule.
+func (svc Service) MaxResponseSize() size.ByteSize {
+	max := svc.ResponseSize
+	for _, phase := range svc.Schedule {
+		if phase.ResponseSize != nil && *phase.ResponseSize > max {
+			max = *phase.ResponseSize
+		}
+	}
+	return max
+}
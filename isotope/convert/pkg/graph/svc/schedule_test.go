@@ -0,0 +1,77 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestService_BehaviourAt(t *testing.T) {
+	var service Service
+	err := json.Unmarshal([]byte(`{
+		"name": "a",
+		"errorRate": "1%",
+		"responseSize": 10,
+		"schedule": [
+			{"from": "5m", "to": "7m", "errorRate": "50%"},
+			{"from": "6m", "to": "8m", "extraSleep": "200ms", "responseSize": 100},
+			{"from": "7m", "extraSleep": "100ms"}
+		]
+	}`), &service)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		elapsed   time.Duration
+		behaviour Behaviour
+	}{
+		{0, Behaviour{ErrorRate: 0.01, ResponseSize: 10}},
+		{5 * time.Minute, Behaviour{ErrorRate: 0.5, ResponseSize: 10}},
+		{6 * time.Minute, Behaviour{
+			ErrorRate: 0.5, ResponseSize: 100, ExtraSleep: 200 * time.Millisecond}},
+		{7 * time.Minute, Behaviour{
+			ErrorRate: 0.01, ResponseSize: 100, ExtraSleep: 300 * time.Millisecond}},
+		{time.Hour, Behaviour{
+			ErrorRate: 0.01, ResponseSize: 10, ExtraSleep: 100 * time.Millisecond}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			behaviour := service.BehaviourAt(test.elapsed)
+			if test.behaviour != behaviour {
+				t.Errorf("expected %v; actual %v", test.behaviour, behaviour)
+			}
+		})
+	}
+
+	if expected, actual := 100, int(service.MaxResponseSize()); expected != actual {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+}
+
+func TestService_UnmarshalJSON_InvalidPhase(t *testing.T) {
+	var service Service
+	err := json.Unmarshal(
+		[]byte(`{"name": "a", "schedule": [{"from": "7m", "to": "5m"}]}`), &service)
+	if _, ok := err.(ErrInvalidPhase); !ok {
+		t.Errorf("expected ErrInvalidPhase; actual %v", err)
+	}
+}
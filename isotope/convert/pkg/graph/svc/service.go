@@ -15,9 +15,13 @@
 package svc
 
 import (
+	"fmt"
+	"hash/fnv"
+
 	"istio.io/tools/isotope/convert/pkg/graph/pct"
 	"istio.io/tools/isotope/convert/pkg/graph/script"
 	"istio.io/tools/isotope/convert/pkg/graph/size"
+	"istio.io/tools/isotope/convert/pkg/graph/svckind"
 	"istio.io/tools/isotope/convert/pkg/graph/svctype"
 )
 
@@ -29,6 +33,27 @@ type Service struct {
 	// Type describes what protocol the service supports (e.g. HTTP, gRPC).
 	Type svctype.ServiceType `json:"type,omitempty"`
 
+	// Namespace is the Kubernetes namespace the service is deployed in. If
+	// unset, it is the service graph namespace.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Cluster is the name of the cluster the service is deployed in. Services
+	// without a cluster all share a single, unnamed one.
+	Cluster string `json:"cluster,omitempty"`
+
+	// Kind describes the backend the service emulates (e.g. a cache). By
+	// default, it runs its script on every request.
+	Kind svckind.ServiceKind `json:"kind,omitempty"`
+
+	// Cache sets the behaviour of a service of kind cache.
+	Cache *Cache `json:"cache,omitempty"`
+
+	// Database sets the behaviour of a service of kind database.
+	Database *Database `json:"database,omitempty"`
+
+	// Queue sets the behaviour of a service of kind queue.
+	Queue *Queue `json:"queue,omitempty"`
+
 	// NumReplicas is the number of replicas backing this service.
 	NumReplicas int32 `json:"numReplicas,omitempty"`
 
@@ -48,4 +73,129 @@ type Service struct {
 
 	// NumRbacPolicies is the number of policies generated for each service.
 	NumRbacPolicies int32 `json:"numRbacPolicies"`
+
+	// MaxConcurrency is the number of requests each replica serves at once. If
+	// unset, the number is unlimited.
+	MaxConcurrency int32 `json:"maxConcurrency,omitempty"`
+
+	// QueueSize is the number of requests beyond MaxConcurrency which wait for
+	// a replica to serve them. Any more requests are rejected with a 503
+	// Service Unavailable.
+	QueueSize int32 `json:"queueSize,omitempty"`
+
+	// Schedule changes the behaviour of the service over time.
+	Schedule []Phase `json:"schedule,omitempty"`
+
+	// Versions are deployed side by side instead of the service itself, each
+	// with its own behaviour. Requests to the service are split between them.
+	Versions []Version `json:"versions,omitempty"`
+}
+
+// Version describes a version of a service. Its settings default to the
+// service's.
+type Version struct {
+	// Name is the value of the version label of the version's pods.
+	Name string `json:"name"`
+
+	// Weight is the percentage of requests to the service routed to this
+	// version. If no version has a weight, requests are split evenly.
+	Weight int32 `json:"weight,omitempty"`
+
+	// NumReplicas is the number of replicas backing this version.
+	NumReplicas int32 `json:"numReplicas"`
+
+	// ErrorRate is the percentage chance between 0 and 1 that this version
+	// should respond with a 500 server error rather than 200 OK.
+	ErrorRate pct.Percentage `json:"errorRate"`
+
+	// ResponseSize is the number of bytes in the response body.
+	ResponseSize size.ByteSize `json:"responseSize"`
+
+	// Script is sequentially called each time this version is called.
+	Script script.Script `json:"script"`
+}
+
+// DeploymentNames returns the names of the Deployments of the service: one
+// per version, named after the service and the version, or one named after
+// the service if it has no versions.
+func (svc Service) DeploymentNames() []string {
+	if len(svc.Versions) == 0 {
+		return []string{svc.Name}
+	}
+	names := make([]string, 0, len(svc.Versions))
+	for _, version := range svc.Versions {
+		names = append(names, VersionDeploymentName(svc.Name, version.Name))
+	}
+	return names
+}
+
+// VersionDeploymentName returns the name of the Deployment of a version of a
+// service.
+func VersionDeploymentName(serviceName string, version string) string {
+	return fmt.Sprintf("%s-%s", serviceName, version)
+}
+
+// DeploymentSeed returns the seed of the Deployment named deploymentName,
+// derived from the seed of the service graph. Each Deployment gets a distinct
+// but stable seed, so that services do not make the same random decisions. A
+// seed of 0 is left unset.
+func DeploymentSeed(seed int64, deploymentName string) int64 {
+	if seed == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d/%s", seed, deploymentName)
+	if derived := int64(h.Sum64()); derived != 0 {
+		return derived
+	}
+	return seed
+}
+
+// Scripts returns the script of the service followed by the scripts of its
+// versions.
+func (svc Service) Scripts() []script.Script {
+	scripts := make([]script.Script, 0, len(svc.Versions)+1)
+	scripts = append(scripts, svc.Script)
+	for _, version := range svc.Versions {
+		scripts = append(scripts, version.Script)
+	}
+	return scripts
+}
+
+// WithVersion returns the service behaving as its version named version.
+func (svc Service) WithVersion(version string) (Service, error) {
+	for _, v := range svc.Versions {
+		if v.Name == version {
+			svc.NumReplicas = v.NumReplicas
+			svc.ErrorRate = v.ErrorRate
+			svc.ResponseSize = v.ResponseSize
+			svc.Script = v.Script
+			svc.Versions = nil
+			return svc, nil
+		}
+	}
+	return Service{}, ErrUnknownVersion{Service: svc.Name, Version: version}
+}
+
+// VersionWeights returns the percentage of requests routed to each version,
+// splitting them evenly when no version has a weight.
+func (svc Service) VersionWeights() []int32 {
+	weights := make([]int32, len(svc.Versions))
+	var total int32
+	for i, v := range svc.Versions {
+		weights[i] = v.Weight
+		total += v.Weight
+	}
+	if total > 0 || len(weights) == 0 {
+		return weights
+	}
+	n := int32(len(weights))
+	for i := range weights {
+		weights[i] = 100 / n
+		// The first versions absorb the remainder so weights add up to 100.
+		if int32(i) < 100%n {
+			weights[i]++
+		}
+	}
+	return weights
 }
@@ -0,0 +1,87 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestService_VersionWeights(t *testing.T) {
+	tests := []struct {
+		versions []Version
+		weights  []int32
+	}{
+		{nil, []int32{}},
+		{[]Version{{Name: "v1"}, {Name: "v2"}}, []int32{50, 50}},
+		{[]Version{{Name: "v1"}, {Name: "v2"}, {Name: "v3"}}, []int32{34, 33, 33}},
+		{[]Version{{Name: "v1", Weight: 80}, {Name: "v2", Weight: 20}}, []int32{80, 20}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			weights := Service{Name: "a", Versions: test.versions}.VersionWeights()
+			if !reflect.DeepEqual(test.weights, weights) {
+				t.Errorf("expected %v; actual %v", test.weights, weights)
+			}
+		})
+	}
+}
+
+func TestService_WithVersion(t *testing.T) {
+	service := Service{
+		Name:        "a",
+		NumReplicas: 3,
+		ErrorRate:   0.1,
+		Versions: []Version{
+			{Name: "v1", NumReplicas: 2, ErrorRate: 0.1},
+			{Name: "v2", NumReplicas: 1, ErrorRate: 0.5, ResponseSize: 10},
+		},
+	}
+
+	expected := Service{Name: "a", NumReplicas: 1, ErrorRate: 0.5, ResponseSize: 10}
+	actual, err := service.WithVersion("v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+
+	expectedErr := ErrUnknownVersion{Service: "a", Version: "v3"}
+	if _, err := service.WithVersion("v3"); err != expectedErr {
+		t.Errorf("expected %v; actual %v", expectedErr, err)
+	}
+}
+
+func TestDeploymentSeed(t *testing.T) {
+	if seed := DeploymentSeed(0, "a"); seed != 0 {
+		t.Errorf("expected %v; actual %v", 0, seed)
+	}
+
+	a := DeploymentSeed(1, "a")
+	if a == 0 || a != DeploymentSeed(1, "a") {
+		t.Errorf("expected a stable, non-zero seed; actual %v", a)
+	}
+	if b := DeploymentSeed(1, "b"); a == b {
+		t.Errorf("expected distinct seeds for a and b; actual %v", b)
+	}
+	if a2 := DeploymentSeed(2, "a"); a == a2 {
+		t.Errorf("expected distinct seeds for seeds 1 and 2; actual %v", a2)
+	}
+}
@@ -17,6 +17,7 @@ package svc
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"istio.io/tools/isotope/convert/pkg/graph/svctype"
 )
@@ -39,11 +40,131 @@ func (svc *Service) UnmarshalJSON(b []byte) (err error) {
 		err = ErrEmptyName
 		return
 	}
+	if svc.MaxConcurrency < 0 || svc.QueueSize < 0 {
+		err = ErrNegativeConcurrencyLimit
+		return
+	}
+	err = svc.validatePlacement()
+	if err != nil {
+		return
+	}
+	err = svc.validateBackend()
+	if err != nil {
+		return
+	}
+	for _, phase := range svc.Schedule {
+		if phase.From < 0 || (phase.To != 0 && phase.To <= phase.From) {
+			err = ErrInvalidPhase{Service: svc.Name, Phase: phase}
+			return
+		}
+	}
+	svc.Versions, err = parseJSONVersions(b, *svc)
 	return
 }
 
+// parseJSONVersions parses the versions of service from b, defaulting their
+// settings to the service's.
+func parseJSONVersions(b []byte, service Service) ([]Version, error) {
+	var rawVersions struct {
+		Versions []json.RawMessage `json:"versions"`
+	}
+	if err := json.Unmarshal(b, &rawVersions); err != nil {
+		return nil, err
+	}
+	if len(rawVersions.Versions) == 0 {
+		return nil, nil
+	}
+
+	versions := make([]Version, 0, len(rawVersions.Versions))
+	names := map[string]bool{}
+	for _, rawVersion := range rawVersions.Versions {
+		version := Version{
+			NumReplicas:  service.NumReplicas,
+			ErrorRate:    service.ErrorRate,
+			ResponseSize: service.ResponseSize,
+			Script:       service.Script,
+		}
+		if err := json.Unmarshal(rawVersion, &version); err != nil {
+			return nil, err
+		}
+		if version.Name == "" {
+			return nil, ErrEmptyVersionName
+		}
+		if names[version.Name] {
+			return nil, ErrDuplicateVersion{Service: service.Name, Version: version.Name}
+		}
+		names[version.Name] = true
+		versions = append(versions, version)
+	}
+
+	var totalWeight int32
+	for _, version := range versions {
+		if version.Weight < 0 {
+			return nil, ErrInvalidVersionWeights{service.Name}
+		}
+		totalWeight += version.Weight
+	}
+	if totalWeight != 0 && totalWeight != 100 {
+		return nil, ErrInvalidVersionWeights{service.Name}
+	}
+	return versions, nil
+}
+
 type unmarshallableService Service
 
 // ErrEmptyName is returned when attempting to parse JSON without an empty name
 // field.
 var ErrEmptyName = errors.New("services must have a name")
+
+// ErrNegativeConcurrencyLimit is returned when the maximum concurrency or the
+// queue size of a service is negative.
+var ErrNegativeConcurrencyLimit = errors.New(
+	"the maxConcurrency and queueSize of services may not be negative")
+
+// ErrInvalidPhase is returned when a phase of the schedule of a service
+// starts before the start of the service or does not end after it starts.
+type ErrInvalidPhase struct {
+	Service string
+	Phase   Phase
+}
+
+func (e ErrInvalidPhase) Error() string {
+	return fmt.Sprintf(
+		`invalid phase of service "%s" from %v to %v`, e.Service, e.Phase.From, e.Phase.To)
+}
+
+// ErrEmptyVersionName is returned when a version of a service has no name.
+var ErrEmptyVersionName = errors.New("service versions must have a name")
+
+// ErrDuplicateVersion is returned when a service has two versions with the
+// same name.
+type ErrDuplicateVersion struct {
+	Service string
+	Version string
+}
+
+func (e ErrDuplicateVersion) Error() string {
+	return fmt.Sprintf(`service "%s" has two versions named "%s"`, e.Service, e.Version)
+}
+
+// ErrInvalidVersionWeights is returned when the weights of the versions of a
+// service are negative or do not add up to 100.
+type ErrInvalidVersionWeights struct {
+	Service string
+}
+
+func (e ErrInvalidVersionWeights) Error() string {
+	return fmt.Sprintf(
+		`the version weights of service "%s" must not be negative and must add up to 100`, e.Service)
+}
+
+// ErrUnknownVersion is returned when looking up a version a service does not
+// have.
+type ErrUnknownVersion struct {
+	Service string
+	Version string
+}
+
+func (e ErrUnknownVersion) Error() string {
+	return fmt.Sprintf(`service "%s" has no version "%s"`, e.Service, e.Version)
+}
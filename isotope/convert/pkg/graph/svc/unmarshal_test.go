@@ -18,7 +18,9 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
+	"istio.io/tools/isotope/convert/pkg/graph/script"
 	"istio.io/tools/isotope/convert/pkg/graph/svctype"
 )
 
@@ -47,6 +49,73 @@ func TestService_UnmarshalJSON(t *testing.T) {
 			Service{Type: svctype.ServiceHTTP, NumReplicas: 1},
 			ErrEmptyName,
 		},
+		{
+			[]byte(`{
+				"name": "A",
+				"numReplicas": 2,
+				"errorRate": 0.5,
+				"script": [{"sleep": "1s"}],
+				"versions": [
+					{"name": "v1", "weight": 90},
+					{"name": "v2", "weight": 10, "numReplicas": 1, "script": []}
+				]
+			}`),
+			Service{
+				Name:        "A",
+				Type:        svctype.ServiceHTTP,
+				NumReplicas: 2,
+				ErrorRate:   0.5,
+				Script:      script.Script{script.SleepCommand(time.Second)},
+				Versions: []Version{
+					{
+						Name:        "v1",
+						Weight:      90,
+						NumReplicas: 2,
+						ErrorRate:   0.5,
+						Script:      script.Script{script.SleepCommand(time.Second)},
+					},
+					{
+						Name:        "v2",
+						Weight:      10,
+						NumReplicas: 1,
+						ErrorRate:   0.5,
+						Script:      script.Script{},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			[]byte(`{"name": "A", "maxConcurrency": 4, "queueSize": 16}`),
+			Service{
+				Name:           "A",
+				Type:           svctype.ServiceHTTP,
+				NumReplicas:    1,
+				MaxConcurrency: 4,
+				QueueSize:      16,
+			},
+			nil,
+		},
+		{
+			[]byte(`{"name": "A", "maxConcurrency": -1}`),
+			Service{Name: "A", Type: svctype.ServiceHTTP, NumReplicas: 1, MaxConcurrency: -1},
+			ErrNegativeConcurrencyLimit,
+		},
+		{
+			[]byte(`{"name": "A", "versions": [{"name": "v1"}, {"name": "v1"}]}`),
+			Service{Name: "A", Type: svctype.ServiceHTTP, NumReplicas: 1},
+			ErrDuplicateVersion{Service: "A", Version: "v1"},
+		},
+		{
+			[]byte(`{"name": "A", "versions": [{"name": "v1", "weight": 50}]}`),
+			Service{Name: "A", Type: svctype.ServiceHTTP, NumReplicas: 1},
+			ErrInvalidVersionWeights{"A"},
+		},
+		{
+			[]byte(`{"name": "A", "versions": [{"weight": 100}]}`),
+			Service{Name: "A", Type: svctype.ServiceHTTP, NumReplicas: 1},
+			ErrEmptyVersionName,
+		},
 	}
 
 	for _, test := range tests {
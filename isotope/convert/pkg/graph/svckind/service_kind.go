@@ -0,0 +1,92 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svckind
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ServiceKind describes the backend a service emulates.
+type ServiceKind int
+
+const (
+	// KindService is the default ServiceKind, a service running its script on
+	// each request.
+	KindService ServiceKind = iota
+	// KindCache answers hits right away and runs its script on misses.
+	KindCache
+	// KindDatabase serves queries over a limited pool of connections.
+	KindDatabase
+	// KindQueue accepts requests right away and runs its script on them later.
+	KindQueue
+)
+
+func (k ServiceKind) String() (s string) {
+	switch k {
+	case KindService:
+		s = "service"
+	case KindCache:
+		s = "cache"
+	case KindDatabase:
+		s = "database"
+	case KindQueue:
+		s = "queue"
+	}
+	return
+}
+
+// MarshalJSON encodes the ServiceKind as a JSON string.
+func (k ServiceKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+// UnmarshalJSON decodes a ServiceKind from a JSON string.
+func (k *ServiceKind) UnmarshalJSON(b []byte) (err error) {
+	var s string
+	err = json.Unmarshal(b, &s)
+	if err != nil {
+		return
+	}
+	*k, err = FromString(s)
+	return
+}
+
+// FromString converts a string to a ServiceKind.
+func FromString(s string) (k ServiceKind, err error) {
+	switch s {
+	case "service":
+		k = KindService
+	case "cache":
+		k = KindCache
+	case "database":
+		k = KindDatabase
+	case "queue":
+		k = KindQueue
+	default:
+		err = InvalidServiceKindStringError{s}
+	}
+	return
+}
+
+// InvalidServiceKindStringError is returned when a string is not parsable to a
+// ServiceKind.
+type InvalidServiceKindStringError struct {
+	String string
+}
+
+func (e InvalidServiceKindStringError) Error() string {
+	return fmt.Sprintf("unknown service kind: %s", e.String)
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svckind
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestServiceKind_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input       []byte
+		serviceKind ServiceKind
+		err         error
+	}{
+		{[]byte(`"service"`), KindService, nil},
+		{[]byte(`"cache"`), KindCache, nil},
+		{[]byte(`"database"`), KindDatabase, nil},
+		{[]byte(`"queue"`), KindQueue, nil},
+		{[]byte(`"cat"`), KindService, InvalidServiceKindStringError{"cat"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			var serviceKind ServiceKind
+			err := json.Unmarshal(test.input, &serviceKind)
+			if test.err != err {
+				t.Errorf("expected %v; actual %v", test.err, err)
+			}
+			if test.serviceKind != serviceKind {
+				t.Errorf("expected %v; actual %v", test.serviceKind, serviceKind)
+			}
+		})
+	}
+}
+
+func TestServiceKind_MarshalJSON(t *testing.T) {
+	output, err := json.Marshal(KindDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `"database"`; expected != string(output) {
+		t.Errorf("expected %s; actual %s", expected, output)
+	}
+}
@@ -30,6 +30,9 @@ const (
 	ServiceHTTP
 	// ServiceGRPC indicates the service should run a GRPC server.
 	ServiceGRPC
+	// ServiceTCP indicates the service should run a TCP server speaking a
+	// length-prefixed request/response protocol.
+	ServiceTCP
 )
 
 func (t ServiceType) String() (s string) {
@@ -38,6 +41,8 @@ func (t ServiceType) String() (s string) {
 		s = "HTTP"
 	case ServiceGRPC:
 		s = "gRPC"
+	case ServiceTCP:
+		s = "TCP"
 	}
 	return
 }
@@ -68,6 +73,8 @@ func FromString(s string) (t ServiceType, err error) {
 		t = ServiceHTTP
 	case "grpc":
 		t = ServiceGRPC
+	case "tcp":
+		t = ServiceTCP
 	default:
 		err = InvalidServiceTypeStringError{s}
 	}
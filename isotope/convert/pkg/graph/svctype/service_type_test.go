@@ -27,6 +27,7 @@ func TestFromString(t *testing.T) {
 	}{
 		{"http", ServiceHTTP, nil},
 		{"grpc", ServiceGRPC, nil},
+		{"tcp", ServiceTCP, nil},
 		{"", ServiceUnknown, InvalidServiceTypeStringError{""}},
 		{"cat", ServiceUnknown, InvalidServiceTypeStringError{"cat"}},
 	}
@@ -55,6 +56,7 @@ func TestServiceType_UnmarshalJSON(t *testing.T) {
 	}{
 		{[]byte(`"http"`), ServiceHTTP, nil},
 		{[]byte(`"grpc"`), ServiceGRPC, nil},
+		{[]byte(`"tcp"`), ServiceTCP, nil},
 		{[]byte(`""`), ServiceUnknown, InvalidServiceTypeStringError{""}},
 		{[]byte(`"cat"`), ServiceUnknown, InvalidServiceTypeStringError{"cat"}},
 	}
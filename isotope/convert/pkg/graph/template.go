@@ -0,0 +1,305 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	templatesKey = "templates"
+	servicesKey  = "services"
+	defaultsKey  = "defaults"
+	scriptKey    = "script"
+	extendsKey   = "extends"
+	useKey       = "use"
+
+	templateNameKey   = "template"
+	templateParamsKey = "params"
+)
+
+var paramRegexp = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+)\}`)
+
+// expandTemplates resolves the templates of a JSON service graph, returning
+// the equivalent JSON without templates.
+//
+// Templates are declared in a top-level "templates" map. A template which is
+// a map is a partial service, which services inherit from with
+// "extends: <name>"; fields set on the service override the template's. A
+// template which is a list is a script fragment, which replaces any
+// "use: <name>" step of a script. Both forms also accept
+// "{template: <name>, params: {<key>: <value>}}", which replaces "${key}"
+// in the template's strings by the value.
+func expandTemplates(b []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if _, ok := doc[includeKey]; ok {
+		return nil, ErrIncludeWithoutFile
+	}
+
+	templates, _ := doc[templatesKey].(map[string]interface{})
+	e := templateExpander{templates: templates, expanding: map[string]bool{}}
+	delete(doc, templatesKey)
+
+	if defaults, ok := doc[defaultsKey].(map[string]interface{}); ok {
+		if err := e.expandScriptOf(defaults); err != nil {
+			return nil, err
+		}
+	}
+
+	if services, ok := doc[servicesKey].([]interface{}); ok {
+		for i, service := range services {
+			service, ok := service.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			expanded, err := e.expandService(service)
+			if err != nil {
+				return nil, err
+			}
+			services[i] = expanded
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+type templateExpander struct {
+	templates map[string]interface{}
+	// expanding holds the templates being expanded, to detect cycles.
+	expanding map[string]bool
+}
+
+// expandService merges the template service extends into service, then
+// expands its script.
+func (e templateExpander) expandService(
+	service map[string]interface{}) (map[string]interface{}, error) {
+	if ref, ok := service[extendsKey]; ok {
+		name, base, err := e.instantiate(ref)
+		if err != nil {
+			return nil, err
+		}
+		baseService, ok := base.(map[string]interface{})
+		if !ok {
+			return nil, ErrTemplateKind{Name: name, Kind: "service"}
+		}
+
+		e.expanding[name] = true
+		baseService, err = e.expandService(baseService)
+		delete(e.expanding, name)
+		if err != nil {
+			return nil, err
+		}
+
+		merged := make(map[string]interface{}, len(baseService)+len(service))
+		for k, v := range baseService {
+			merged[k] = v
+		}
+		for k, v := range service {
+			merged[k] = v
+		}
+		delete(merged, extendsKey)
+		service = merged
+	}
+
+	if err := e.expandScriptOf(service); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+func (e templateExpander) expandScriptOf(m map[string]interface{}) error {
+	steps, ok := m[scriptKey].([]interface{})
+	if !ok {
+		return nil
+	}
+	expanded, err := e.expandSteps(steps)
+	if err != nil {
+		return err
+	}
+	m[scriptKey] = expanded
+	return nil
+}
+
+// expandSteps replaces each "use" step in steps, including those nested in
+// concurrent steps, by the steps of its template.
+func (e templateExpander) expandSteps(steps []interface{}) ([]interface{}, error) {
+	expanded := make([]interface{}, 0, len(steps))
+	for _, step := range steps {
+		switch step := step.(type) {
+		case []interface{}:
+			subSteps, err := e.expandSteps(step)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, subSteps)
+		case map[string]interface{}:
+			ref, ok := step[useKey]
+			if !ok {
+				expanded = append(expanded, step)
+				continue
+			}
+			if len(step) > 1 {
+				return nil, fmt.Errorf("%q may not be combined with other keys in a step", useKey)
+			}
+
+			name, fragment, err := e.instantiate(ref)
+			if err != nil {
+				return nil, err
+			}
+			fragmentSteps, ok := fragment.([]interface{})
+			if !ok {
+				return nil, ErrTemplateKind{Name: name, Kind: "script"}
+			}
+
+			e.expanding[name] = true
+			fragmentSteps, err = e.expandSteps(fragmentSteps)
+			delete(e.expanding, name)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, fragmentSteps...)
+		default:
+			expanded = append(expanded, step)
+		}
+	}
+	return expanded, nil
+}
+
+// instantiate returns a copy of the template referenced by ref, which is
+// either the template's name or a map with its name and parameters.
+func (e templateExpander) instantiate(ref interface{}) (string, interface{}, error) {
+	var name string
+	var params map[string]interface{}
+	switch ref := ref.(type) {
+	case string:
+		name = ref
+	case map[string]interface{}:
+		name, _ = ref[templateNameKey].(string)
+		params, _ = ref[templateParamsKey].(map[string]interface{})
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("invalid template reference: %v", ref)
+	}
+
+	template, ok := e.templates[name]
+	if !ok {
+		return "", nil, ErrUnknownTemplate{name}
+	}
+	if e.expanding[name] {
+		return "", nil, ErrTemplateCycle{name}
+	}
+	instance, err := substituteParams(template, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, instance, nil
+}
+
+// substituteParams returns a deep copy of v where each "${key}" in strings is
+// replaced by params[key]. A string which is exactly "${key}" is replaced by
+// the value itself, so that parameters may be numbers.
+func substituteParams(
+	v interface{}, params map[string]interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, sub := range v {
+			substituted, err := substituteParams(sub, params)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = substituted
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, 0, len(v))
+		for _, sub := range v {
+			substituted, err := substituteParams(sub, params)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, substituted)
+		}
+		return l, nil
+	case string:
+		if match := paramRegexp.FindStringSubmatch(v); match != nil && match[0] == v {
+			value, ok := params[match[1]]
+			if !ok {
+				return nil, ErrUnresolvedParameter{match[1]}
+			}
+			return value, nil
+		}
+		var err error
+		s := paramRegexp.ReplaceAllStringFunc(v, func(param string) string {
+			key := strings.TrimSuffix(strings.TrimPrefix(param, "${"), "}")
+			value, ok := params[key]
+			if !ok {
+				err = ErrUnresolvedParameter{key}
+				return param
+			}
+			return fmt.Sprint(value)
+		})
+		return s, err
+	default:
+		return v, nil
+	}
+}
+
+// ErrUnknownTemplate is returned when a service or a script references a
+// template which is not defined.
+type ErrUnknownTemplate struct {
+	Name string
+}
+
+func (e ErrUnknownTemplate) Error() string {
+	return fmt.Sprintf(`unknown template "%s"`, e.Name)
+}
+
+// ErrTemplateCycle is returned when a template references itself, directly or
+// through other templates.
+type ErrTemplateCycle struct {
+	Name string
+}
+
+func (e ErrTemplateCycle) Error() string {
+	return fmt.Sprintf(`template "%s" references itself`, e.Name)
+}
+
+// ErrTemplateKind is returned when a script template is extended by a service
+// or a service template is used in a script.
+type ErrTemplateKind struct {
+	Name string
+	Kind string
+}
+
+func (e ErrTemplateKind) Error() string {
+	return fmt.Sprintf(`template "%s" is not a %s template`, e.Name, e.Kind)
+}
+
+// ErrUnresolvedParameter is returned when a template references a parameter
+// which is not given.
+type ErrUnresolvedParameter struct {
+	Name string
+}
+
+func (e ErrUnresolvedParameter) Error() string {
+	return fmt.Sprintf(`template parameter "%s" is not set`, e.Name)
+}
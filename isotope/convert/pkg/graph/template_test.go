@@ -0,0 +1,159 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph/pct"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
+)
+
+func TestServiceGraph_UnmarshalJSON_Templates(t *testing.T) {
+	tests := []struct {
+		input []byte
+		graph ServiceGraph
+		err   error
+	}{
+		{jsonWithTemplates, graphWithTemplates, nil},
+		{
+			[]byte(`{"services": [{"name": "a", "extends": "leaf"}]}`),
+			ServiceGraph{},
+			ErrUnknownTemplate{"leaf"},
+		},
+		{
+			[]byte(`{
+				"templates": {"loop": [{"use": "loop"}]},
+				"services": [{"name": "a", "script": [{"use": "loop"}]}]
+			}`),
+			ServiceGraph{},
+			ErrTemplateCycle{"loop"},
+		},
+		{
+			[]byte(`{
+				"templates": {"wait": [{"sleep": "${duration}"}]},
+				"services": [{"name": "a", "script": [{"use": "wait"}]}]
+			}`),
+			ServiceGraph{},
+			ErrUnresolvedParameter{"duration"},
+		},
+		{
+			[]byte(`{
+				"templates": {"wait": [{"sleep": "10ms"}]},
+				"services": [{"name": "a", "extends": "wait"}]
+			}`),
+			ServiceGraph{},
+			ErrTemplateKind{Name: "wait", Kind: "service"},
+		},
+		{
+			[]byte(`{"include": ["b.yaml"], "services": [{"name": "a"}]}`),
+			ServiceGraph{},
+			ErrIncludeWithoutFile,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			var graph ServiceGraph
+			err := json.Unmarshal(test.input, &graph)
+			if test.err != err {
+				t.Errorf("expected %v; actual %v", test.err, err)
+			}
+			if err == nil && !reflect.DeepEqual(test.graph, graph) {
+				t.Errorf("expected %v; actual %v", test.graph, graph)
+			}
+		})
+	}
+}
+
+var (
+	jsonWithTemplates = []byte(`
+		{
+			"templates": {
+				"leaf": {
+					"numReplicas": 2,
+					"errorRate": "10%",
+					"script": [{"sleep": "${duration}"}]
+				},
+				"fan-out": [
+					[{"call": "${first}"}, {"call": "${second}"}],
+					{"use": {"template": "wait", "params": {"duration": "1ms"}}}
+				],
+				"wait": [{"sleep": "${duration}"}]
+			},
+			"services": [
+				{
+					"name": "a",
+					"extends": {"template": "leaf", "params": {"duration": "10ms"}}
+				},
+				{
+					"name": "b",
+					"extends": {"template": "leaf", "params": {"duration": "20ms"}},
+					"numReplicas": 3
+				},
+				{
+					"name": "c",
+					"isEntrypoint": true,
+					"script": [
+						{"use": {"template": "fan-out", "params": {"first": "a", "second": "b"}}},
+						{"call": "a"}
+					]
+				}
+			]
+		}
+	`)
+	graphWithTemplates = ServiceGraph{[]svc.Service{
+		{
+			Name:        "a",
+			Type:        svctype.ServiceHTTP,
+			NumReplicas: 2,
+			ErrorRate:   pct.Percentage(0.1),
+			Script: script.Script{
+				script.SleepCommand(10 * time.Millisecond),
+			},
+		},
+		{
+			Name:        "b",
+			Type:        svctype.ServiceHTTP,
+			NumReplicas: 3,
+			ErrorRate:   pct.Percentage(0.1),
+			Script: script.Script{
+				script.SleepCommand(20 * time.Millisecond),
+			},
+		},
+		{
+			Name:         "c",
+			Type:         svctype.ServiceHTTP,
+			NumReplicas:  1,
+			IsEntrypoint: true,
+			Script: script.Script{
+				script.ConcurrentCommand{
+					script.RequestCommand{ServiceName: "a"},
+					script.RequestCommand{ServiceName: "b"},
+				},
+				script.SleepCommand(time.Millisecond),
+				script.RequestCommand{ServiceName: "a"},
+			},
+		},
+	}}
+)
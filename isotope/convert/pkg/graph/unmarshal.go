@@ -26,8 +26,14 @@ import (
 )
 
 // UnmarshalJSON converts b into a valid ServiceGraph. See validate() for the
-// details on what it means to be "valid".
+// details on what it means to be "valid". Templates are expanded first (see
+// expandTemplates()).
 func (g *ServiceGraph) UnmarshalJSON(b []byte) (err error) {
+	b, err = expandTemplates(b)
+	if err != nil {
+		return
+	}
+
 	metadata := serviceGraphJSONMetadata{Defaults: defaultDefaults}
 	err = json.Unmarshal(b, &metadata)
 	if err != nil {
@@ -83,6 +89,10 @@ type defaults struct {
 	RequestSize     size.ByteSize       `json:"requestSize"`
 	NumReplicas     int32               `json:"numReplicas"`
 	NumRbacPolicies int32               `json:"numRbacPolicies"`
+	MaxConcurrency  int32               `json:"maxConcurrency"`
+	QueueSize       int32               `json:"queueSize"`
+	Namespace       string              `json:"namespace"`
+	Cluster         string              `json:"cluster"`
 }
 
 func withGlobalDefaults(defaults defaults, f func()) {
@@ -96,6 +106,10 @@ func withGlobalDefaults(defaults defaults, f func()) {
 		ResponseSize:    defaults.ResponseSize,
 		Script:          defaults.Script,
 		NumRbacPolicies: defaults.NumRbacPolicies,
+		MaxConcurrency:  defaults.MaxConcurrency,
+		QueueSize:       defaults.QueueSize,
+		Namespace:       defaults.Namespace,
+		Cluster:         defaults.Cluster,
 	}
 
 	origDefaultRequestCommand := script.DefaultRequestCommand
@@ -39,6 +39,21 @@ func TestServiceGraph_UnmarshalJSON(t *testing.T) {
 			ServiceGraph{},
 			ErrRequestToUndefinedService{"b"},
 		},
+		{
+			[]byte(`{"services": [{"name": "a", "versions": [{"name": "v1", "script": [{"call": "b"}]}]}]}`),
+			ServiceGraph{},
+			ErrRequestToUndefinedService{"b"},
+		},
+		{
+			[]byte(`{"services": [{"name": "a", "versions": [{"name": "b"}, {"name": "c"}]}, {"name": "a-b"}]}`),
+			ServiceGraph{},
+			ErrDeploymentNameCollision{Name: "a-b", Services: [2]string{"a", "a-b"}},
+		},
+		{
+			[]byte(`{"services": [{"name": "a-b", "namespace": "other"}, {"name": "a", "versions": [{"name": "b"}]}]}`),
+			ServiceGraph{},
+			ErrDeploymentNameCollision{Name: "a-b", Services: [2]string{"a-b", "a"}},
+		},
 		{
 			jsonWithNestedConcurrentCommand,
 			ServiceGraph{},
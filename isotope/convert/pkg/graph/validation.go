@@ -25,14 +25,33 @@ import (
 // g is valid if a ServiceGraph:
 // - Each of its services only makes requests to other defined services.
 // - ConcurrentCommands do not contain other ConcurrentCommands.
+// - The Deployments of its services have distinct names.
 func validate(g ServiceGraph) error {
 	svcNames := map[string]bool{}
 	for _, svc := range g.Services {
 		svcNames[svc.Name] = true
 	}
 	for _, svc := range g.Services {
-		if err := validateCommands(svc.Script, svcNames); err != nil {
-			return err
+		for _, script := range svc.Scripts() {
+			if err := validateCommands(script, svcNames); err != nil {
+				return err
+			}
+		}
+	}
+	return validateDeploymentNames(g)
+}
+
+// validateDeploymentNames returns an error if two services of g have a
+// Deployment of the same name. Names are unique across namespaces and
+// clusters, as the replicas of the Helm chart are set by Deployment name.
+func validateDeploymentNames(g ServiceGraph) error {
+	deployments := map[string]string{}
+	for _, svc := range g.Services {
+		for _, name := range svc.DeploymentNames() {
+			if other, ok := deployments[name]; ok && other != svc.Name {
+				return ErrDeploymentNameCollision{Name: name, Services: [2]string{other, svc.Name}}
+			}
+			deployments[name] = svc.Name
 		}
 	}
 	return nil
@@ -80,3 +99,15 @@ func (e ErrRequestToUndefinedService) Error() string {
 // a ConcurrentCommand.
 var ErrNestedConcurrentCommand = errors.New(
 	"concurrent commands may not be nested")
+
+// ErrDeploymentNameCollision is returned when the Deployments of two services,
+// or of their versions, have the same name.
+type ErrDeploymentNameCollision struct {
+	Name     string
+	Services [2]string
+}
+
+func (e ErrDeploymentNameCollision) Error() string {
+	return fmt.Sprintf(`services "%s" and "%s" both have a Deployment named "%s"`,
+		e.Services[0], e.Services[1], e.Name)
+}
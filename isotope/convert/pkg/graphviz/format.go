@@ -0,0 +1,114 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+)
+
+// Format is a format a Graph can be rendered to.
+type Format string
+
+const (
+	// FormatDOT is the Graphviz DOT language.
+	FormatDOT Format = "dot"
+	// FormatMermaid is a Mermaid flowchart, e.g. for Markdown documents.
+	FormatMermaid Format = "mermaid"
+	// FormatPlantUML is a PlantUML diagram.
+	FormatPlantUML Format = "plantuml"
+	// FormatCytoscape is JSON in the Cytoscape.js elements format.
+	FormatCytoscape Format = "cytoscape"
+	// FormatD3 is JSON with the nodes and links of a D3 force layout.
+	FormatD3 Format = "d3"
+	// FormatHTML is a self-contained, interactive HTML page.
+	FormatHTML Format = "html"
+)
+
+// Formats lists every supported Format.
+var Formats = []Format{
+	FormatDOT, FormatMermaid, FormatPlantUML, FormatCytoscape, FormatD3, FormatHTML,
+}
+
+// ServiceGraphToFormat converts a ServiceGraph to a string in the format.
+func ServiceGraphToFormat(
+	serviceGraph graph.ServiceGraph, format Format) (string, error) {
+	g, err := ServiceGraphToGraph(serviceGraph)
+	if err != nil {
+		return "", err
+	}
+	return GraphToFormat(g, format)
+}
+
+// GraphToFormat converts a graphviz graph to a string in the format.
+func GraphToFormat(g Graph, format Format) (string, error) {
+	switch format {
+	case FormatDOT:
+		return GraphToDotLanguage(g)
+	case FormatMermaid:
+		return GraphToMermaid(g)
+	case FormatPlantUML:
+		return GraphToPlantUML(g)
+	case FormatCytoscape:
+		return GraphToCytoscapeJSON(g)
+	case FormatD3:
+		return GraphToD3JSON(g)
+	case FormatHTML:
+		return GraphToHTML(g)
+	default:
+		return "", fmt.Errorf("unknown graph format %q", format)
+	}
+}
+
+// nodeIDFuncs returns template functions mapping node names to identifiers
+// which are safe to use in any format, regardless of the characters in the
+// names.
+func nodeIDFuncs(g Graph) template.FuncMap {
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node.Name] = fmt.Sprintf("n%d", i)
+	}
+	return template.FuncMap{
+		"id": func(name string) string { return ids[name] },
+	}
+}
+
+// mermaidEscaper escapes text inside the quoted labels of a Mermaid flowchart
+// with Mermaid's entity codes, so that names containing e.g. `"` or `]` do not
+// end the label early.
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	`"`, "#quot;",
+	"<", "#lt;",
+	">", "#gt;",
+)
+
+// plantUMLEscaper escapes text inside the strings of a PlantUML diagram with
+// Unicode escapes, so that names containing e.g. `"` or `\n` are shown as is.
+var plantUMLEscaper = strings.NewReplacer(
+	`"`, "<U+0022>",
+	`\`, "<U+005C>",
+	"\n", " ",
+)
+
+// escapeFuncs returns template functions escaping text for the formats.
+func escapeFuncs(funcs template.FuncMap) template.FuncMap {
+	funcs["mermaid"] = mermaidEscaper.Replace
+	funcs["plantuml"] = plantUMLEscaper.Replace
+	return funcs
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var formatTestGraph = Graph{
+	Nodes: []Node{
+		{Name: "a", Type: "HTTP", ErrorRate: "0.00%", ResponseSize: "1KiB"},
+		{Name: "b-1", Type: "gRPC", ErrorRate: "10.00%", ResponseSize: "1KiB",
+			Steps: [][]string{{"CALL \"a\" 2KiB"}}},
+	},
+	Edges: []Edge{
+		{From: "b-1", To: "a", StepIndex: 0, Size: "2KiB", Probability: "50%"},
+	},
+}
+
+func TestGraphToFormat(t *testing.T) {
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			FormatMermaid,
+			`flowchart LR
+  n0["a<br/>HTTP, err 0.00%"]
+  n1["b-1<br/>gRPC, err 10.00%"]
+  n1 -->|"2KiB, 50%"| n0
+`,
+		},
+		{
+			FormatPlantUML,
+			`@startuml
+rectangle "a\nHTTP, err 0.00%" as n0
+rectangle "b-1\ngRPC, err 10.00%" as n1
+n1 --> n0 : 2KiB, 50%
+@enduml
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(string(test.format), func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := GraphToFormat(formatTestGraph, test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.expected != actual {
+				t.Errorf("expected %v; actual %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGraphToFormat_Escaping(t *testing.T) {
+	g := Graph{
+		Nodes: []Node{
+			{Name: `a"b|c]d:e`, Type: "HTTP", ErrorRate: "0.00%"},
+			{Name: `x\ny#1`, Type: "HTTP", ErrorRate: "0.00%"},
+		},
+		Edges: []Edge{
+			{From: `a"b|c]d:e`, To: `x\ny#1`, Size: `"1KiB"`, Probability: "<50%>"},
+		},
+	}
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			FormatMermaid,
+			`flowchart LR
+  n0["a#quot;b|c]d:e<br/>HTTP, err 0.00%"]
+  n1["x\ny#35;1<br/>HTTP, err 0.00%"]
+  n0 -->|"#quot;1KiB#quot;, #lt;50%#gt;"| n1
+`,
+		},
+		{
+			FormatPlantUML,
+			`@startuml
+rectangle "a<U+0022>b|c]d:e\nHTTP, err 0.00%" as n0
+rectangle "x<U+005C>ny#1\nHTTP, err 0.00%" as n1
+n0 --> n1 : <U+0022>1KiB<U+0022>, <50%>
+@enduml
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(string(test.format), func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := GraphToFormat(g, test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.expected != actual {
+				t.Errorf("expected %v; actual %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGraphToFormat_JSON(t *testing.T) {
+	expectedLink := jsonEdge{
+		Source:      "b-1",
+		Target:      "a",
+		StepIndex:   0,
+		Size:        "2KiB",
+		Probability: "50%",
+		Label:       "2KiB, 50%",
+	}
+
+	d3JSON, err := GraphToFormat(formatTestGraph, FormatD3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d3 d3Graph
+	if err := json.Unmarshal([]byte(d3JSON), &d3); err != nil {
+		t.Fatal(err)
+	}
+	if len(d3.Nodes) != 2 || !reflect.DeepEqual([]jsonEdge{expectedLink}, d3.Links) {
+		t.Errorf("unexpected D3 graph %+v", d3)
+	}
+
+	cytoscapeJSON, err := GraphToFormat(formatTestGraph, FormatCytoscape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cytoscape cytoscapeGraph
+	if err := json.Unmarshal([]byte(cytoscapeJSON), &cytoscape); err != nil {
+		t.Fatal(err)
+	}
+	expectedLink.ID = "e0"
+	if len(cytoscape.Elements.Nodes) != 2 ||
+		!reflect.DeepEqual([]cytoscapeEdge{{expectedLink}}, cytoscape.Elements.Edges) {
+		t.Errorf("unexpected Cytoscape graph %+v", cytoscape)
+	}
+}
+
+func TestGraphToFormat_HTML(t *testing.T) {
+	html, err := GraphToFormat(formatTestGraph, FormatHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `"label":"2KiB, 50%"`) {
+		t.Errorf("expected the graph to be embedded in %v", html)
+	}
+}
+
+func TestGraphToFormat_Unknown(t *testing.T) {
+	if _, err := GraphToFormat(formatTestGraph, Format("png")); err == nil {
+		t.Error("expected an error; actual nil")
+	}
+}
@@ -12,7 +12,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package graphviz converts service graphs into Graphviz DOT language.
+// Package graphviz converts service graphs into Graphviz DOT language and other
+// graph description formats.
 package graphviz
 
 import (
@@ -23,6 +24,7 @@ import (
 	"istio.io/tools/isotope/convert/pkg/graph"
 	"istio.io/tools/isotope/convert/pkg/graph/script"
 	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svckind"
 )
 
 // ServiceGraphToDotLanguage converts a ServiceGraph to a Graphviz DOT language
@@ -84,6 +86,7 @@ type Graph struct {
 type Node struct {
 	Name         string
 	Type         string
+	Kind         string
 	ErrorRate    string
 	ResponseSize string
 	Steps        [][]string
@@ -91,9 +94,17 @@ type Node struct {
 
 // Edge represents a directed edge in the Graphviz graph.
 type Edge struct {
-	From      string
-	To        string
-	StepIndex int
+	From        string
+	To          string
+	StepIndex   int
+	Size        string
+	Probability string
+}
+
+// Label describes the request sent along the edge, i.e. its size and the
+// chance it is sent.
+func (e Edge) Label() string {
+	return fmt.Sprintf("%s, %s", e.Size, e.Probability)
 }
 
 const graphvizTemplate = `digraph {
@@ -106,7 +117,7 @@ const graphvizTemplate = `digraph {
   {{ range .Nodes -}}
   "{{ .Name }}" [label=<
 <TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0">
-  <TR><TD><B>{{ .Name }}</B><BR />Type: {{ .Type }}<BR />Err: {{ .ErrorRate }}</TD></TR>
+  <TR><TD><B>{{ .Name }}</B><BR />Type: {{ .Type }}{{ if .Kind }}<BR />Kind: {{ .Kind }}{{ end }}<BR />Err: {{ .ErrorRate }}</TD></TR>
   {{- range $i, $cmds := .Steps }}
   <TR><TD PORT="{{ $i }}">
   {{- range $j, $cmd := $cmds -}}
@@ -120,7 +131,7 @@ const graphvizTemplate = `digraph {
   {{ end }}
 
   {{- range .Edges }}
-  "{{ .From -}}":{{- .StepIndex }} -> "{{ .To }}"
+  "{{ .From -}}":{{- .StepIndex }} -> "{{ .To }}" [label="{{ .Label }}"]
   {{- end }}
 }
 `
@@ -135,9 +146,11 @@ func getEdgesFromExe(
 		}
 	case script.RequestCommand:
 		e := Edge{
-			From:      fromServiceName,
-			To:        cmd.ServiceName,
-			StepIndex: idx,
+			From:        fromServiceName,
+			To:          cmd.ServiceName,
+			StepIndex:   idx,
+			Size:        cmd.Size.String(),
+			Probability: cmd.ProbabilityString(),
 		}
 		edges = append(edges, e)
 	}
@@ -157,9 +170,15 @@ func toGraphvizNode(service svc.Service) (Node, []Edge, error) {
 		stepEdges := getEdgesFromExe(exe, idx, service.Name)
 		edges = append(edges, stepEdges...)
 	}
+	// Only the kinds of backends are shown, plain services being the norm.
+	kind := ""
+	if service.Kind != svckind.KindService {
+		kind = service.Kind.String()
+	}
 	n := Node{
 		Name:         service.Name,
 		Type:         service.Type.String(),
+		Kind:         kind,
 		ErrorRate:    service.ErrorRate.String(),
 		ResponseSize: service.ResponseSize.String(),
 		Steps:        steps,
@@ -81,29 +81,39 @@ func TestServiceGraphToGraph(t *testing.T) {
 		},
 		Edges: []Edge{
 			{
-				From:      "c",
-				To:        "a",
-				StepIndex: 0,
+				From:        "c",
+				To:          "a",
+				StepIndex:   0,
+				Size:        "10KiB",
+				Probability: "100%",
 			},
 			{
-				From:      "c",
-				To:        "b",
-				StepIndex: 1,
+				From:        "c",
+				To:          "b",
+				StepIndex:   1,
+				Size:        "1KiB",
+				Probability: "100%",
 			},
 			{
-				From:      "d",
-				To:        "a",
-				StepIndex: 0,
+				From:        "d",
+				To:          "a",
+				StepIndex:   0,
+				Size:        "1KiB",
+				Probability: "100%",
 			},
 			{
-				From:      "d",
-				To:        "c",
-				StepIndex: 0,
+				From:        "d",
+				To:          "c",
+				StepIndex:   0,
+				Size:        "1KiB",
+				Probability: "100%",
 			},
 			{
-				From:      "d",
-				To:        "b",
-				StepIndex: 2,
+				From:        "d",
+				To:          "b",
+				StepIndex:   2,
+				Size:        "1KiB",
+				Probability: "100%",
 			},
 		},
 	}
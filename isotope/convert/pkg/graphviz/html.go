@@ -0,0 +1,190 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// GraphToHTML converts a graphviz graph to a self-contained HTML page which
+// lays the graph out with a force simulation. Nodes can be dragged, and
+// hovering a node or an edge shows its details.
+func GraphToHTML(g Graph) (string, error) {
+	tmpl, err := template.New("html").Parse(htmlTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, toD3Graph(g))
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Service Graph</title>
+<style>
+  html, body { margin: 0; height: 100%; font-family: courier, monospace; }
+  svg { width: 100%; height: 100%; }
+  .edge { stroke: #999; stroke-width: 1.5px; }
+  .edge:hover { stroke: #d62728; stroke-width: 3px; }
+  .node circle { fill: #1f77b4; stroke: #fff; stroke-width: 1.5px; cursor: move; }
+  .node text { font-size: 12px; pointer-events: none; }
+</style>
+</head>
+<body>
+<svg id="graph">
+  <defs>
+    <marker id="arrow" viewBox="0 -5 10 10" refX="18" markerWidth="6"
+        markerHeight="6" orient="auto">
+      <path d="M0,-5L10,0L0,5" fill="#999"></path>
+    </marker>
+  </defs>
+</svg>
+<script>
+(function() {
+  var graph = {{ . }};
+  var svgNS = "http://www.w3.org/2000/svg";
+  var svg = document.getElementById("graph");
+  var width = svg.clientWidth, height = svg.clientHeight;
+
+  var nodesByID = {};
+  graph.nodes.forEach(function(n, i) {
+    var angle = 2 * Math.PI * i / graph.nodes.length;
+    n.x = width / 2 + Math.cos(angle) * width / 4;
+    n.y = height / 2 + Math.sin(angle) * height / 4;
+    n.vx = 0;
+    n.vy = 0;
+    nodesByID[n.id] = n;
+  });
+
+  function element(name, attrs, parent) {
+    var e = document.createElementNS(svgNS, name);
+    Object.keys(attrs).forEach(function(k) { e.setAttribute(k, attrs[k]); });
+    parent.appendChild(e);
+    return e;
+  }
+
+  function title(text, parent) {
+    element("title", {}, parent).textContent = text;
+  }
+
+  graph.links.forEach(function(l) {
+    l.line = element("line", {"class": "edge", "marker-end": "url(#arrow)"}, svg);
+    title(l.source + " -> " + l.target + " (step " + l.stepIndex + "): " + l.label, l.line);
+  });
+  graph.nodes.forEach(function(n) {
+    n.group = element("g", {"class": "node"}, svg);
+    element("circle", {r: 8}, n.group);
+    element("text", {x: 11, y: 4}, n.group).textContent = n.id;
+    var steps = (n.steps || []).map(function(s, i) { return i + ": " + s.join(" | "); });
+    title([n.id, "Type: " + n.type, "Err: " + n.errorRate,
+      "Response: " + n.responseSize].concat(steps).join("\n"), n.group);
+    n.group.addEventListener("mousedown", function(event) {
+      event.preventDefault();
+      dragged = n;
+      restart();
+    });
+  });
+
+  var dragged = null;
+  svg.addEventListener("mousemove", function(event) {
+    if (dragged) {
+      var rect = svg.getBoundingClientRect();
+      dragged.x = event.clientX - rect.left;
+      dragged.y = event.clientY - rect.top;
+      render();
+    }
+  });
+  window.addEventListener("mouseup", function() { dragged = null; });
+
+  var alpha = 1;
+  function tick() {
+    var nodes = graph.nodes, i, j;
+    for (i = 0; i < nodes.length; i++) {
+      for (j = i + 1; j < nodes.length; j++) {
+        var a = nodes[i], b = nodes[j];
+        var dx = b.x - a.x, dy = b.y - a.y;
+        var d2 = Math.max(dx * dx + dy * dy, 1);
+        var f = 800 * alpha / d2;
+        a.vx -= dx * f; a.vy -= dy * f;
+        b.vx += dx * f; b.vy += dy * f;
+      }
+    }
+    graph.links.forEach(function(l) {
+      var s = nodesByID[l.source], t = nodesByID[l.target];
+      var dx = t.x - s.x, dy = t.y - s.y;
+      var d = Math.max(Math.sqrt(dx * dx + dy * dy), 1);
+      var f = (d - 80) / d * 0.05 * alpha;
+      s.vx += dx * f; s.vy += dy * f;
+      t.vx -= dx * f; t.vy -= dy * f;
+    });
+    nodes.forEach(function(n) {
+      n.vx += (width / 2 - n.x) * 0.005 * alpha;
+      n.vy += (height / 2 - n.y) * 0.005 * alpha;
+      if (n !== dragged) {
+        n.x += n.vx;
+        n.y += n.vy;
+      }
+      n.vx *= 0.6;
+      n.vy *= 0.6;
+    });
+  }
+
+  function render() {
+    graph.links.forEach(function(l) {
+      var s = nodesByID[l.source], t = nodesByID[l.target];
+      l.line.setAttribute("x1", s.x);
+      l.line.setAttribute("y1", s.y);
+      l.line.setAttribute("x2", t.x);
+      l.line.setAttribute("y2", t.y);
+    });
+    graph.nodes.forEach(function(n) {
+      n.group.setAttribute("transform", "translate(" + n.x + "," + n.y + ")");
+    });
+  }
+
+  var running = false;
+  function step() {
+    tick();
+    render();
+    alpha *= 0.98;
+    if (alpha > 0.01 || dragged) {
+      window.requestAnimationFrame(step);
+    } else {
+      running = false;
+    }
+  }
+
+  function restart() {
+    alpha = Math.max(alpha, 0.3);
+    if (!running) {
+      running = true;
+      window.requestAnimationFrame(step);
+    }
+  }
+
+  restart();
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,127 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type jsonNode struct {
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	ErrorRate    string     `json:"errorRate"`
+	ResponseSize string     `json:"responseSize"`
+	Steps        [][]string `json:"steps"`
+}
+
+type jsonEdge struct {
+	ID          string `json:"id,omitempty"`
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	StepIndex   int    `json:"stepIndex"`
+	Size        string `json:"size"`
+	Probability string `json:"probability"`
+	Label       string `json:"label"`
+}
+
+type cytoscapeGraph struct {
+	Elements cytoscapeElements `json:"elements"`
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeNode `json:"nodes"`
+	Edges []cytoscapeEdge `json:"edges"`
+}
+
+type cytoscapeNode struct {
+	Data jsonNode `json:"data"`
+}
+
+type cytoscapeEdge struct {
+	Data jsonEdge `json:"data"`
+}
+
+type d3Graph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Links []jsonEdge `json:"links"`
+}
+
+// GraphToCytoscapeJSON converts a graphviz graph to JSON in the Cytoscape.js
+// elements format.
+func GraphToCytoscapeJSON(g Graph) (string, error) {
+	elements := cytoscapeElements{
+		Nodes: make([]cytoscapeNode, 0, len(g.Nodes)),
+		Edges: make([]cytoscapeEdge, 0, len(g.Edges)),
+	}
+	for _, node := range g.Nodes {
+		elements.Nodes = append(elements.Nodes, cytoscapeNode{toJSONNode(node)})
+	}
+	for i, edge := range g.Edges {
+		e := toJSONEdge(edge)
+		e.ID = fmt.Sprintf("e%d", i)
+		elements.Edges = append(elements.Edges, cytoscapeEdge{e})
+	}
+	return marshalIndent(cytoscapeGraph{elements})
+}
+
+// GraphToD3JSON converts a graphviz graph to JSON with the nodes and links of
+// a D3 force layout.
+func GraphToD3JSON(g Graph) (string, error) {
+	return marshalIndent(toD3Graph(g))
+}
+
+func toD3Graph(g Graph) d3Graph {
+	d3 := d3Graph{
+		Nodes: make([]jsonNode, 0, len(g.Nodes)),
+		Links: make([]jsonEdge, 0, len(g.Edges)),
+	}
+	for _, node := range g.Nodes {
+		d3.Nodes = append(d3.Nodes, toJSONNode(node))
+	}
+	for _, edge := range g.Edges {
+		d3.Links = append(d3.Links, toJSONEdge(edge))
+	}
+	return d3
+}
+
+func toJSONNode(node Node) jsonNode {
+	return jsonNode{
+		ID:           node.Name,
+		Type:         node.Type,
+		ErrorRate:    node.ErrorRate,
+		ResponseSize: node.ResponseSize,
+		Steps:        node.Steps,
+	}
+}
+
+func toJSONEdge(edge Edge) jsonEdge {
+	return jsonEdge{
+		Source:      edge.From,
+		Target:      edge.To,
+		StepIndex:   edge.StepIndex,
+		Size:        edge.Size,
+		Probability: edge.Probability,
+		Label:       edge.Label(),
+	}
+}
+
+func marshalIndent(v interface{}) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphviz
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// GraphToMermaid converts a graphviz graph to a Mermaid flowchart.
+func GraphToMermaid(g Graph) (string, error) {
+	tmpl, err := template.New("mermaid").Funcs(escapeFuncs(nodeIDFuncs(g))).Parse(mermaidTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, g)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+const mermaidTemplate = `flowchart LR
+{{- range .Nodes }}
+  {{ id .Name }}["{{ mermaid .Name }}<br/>{{ mermaid .Type }}, err {{ mermaid .ErrorRate }}"]
+{{- end }}
+{{- range .Edges }}
+  {{ id .From }} -->|"{{ mermaid .Label }}"| {{ id .To }}
+{{- end }}
+`
@@ -32,7 +32,6 @@ func makeFortioDeployment(
 	deployment.Kind = "Deployment"
 	deployment.ObjectMeta.Name = "client"
 	deployment.ObjectMeta.Labels = fortioClientLabels
-	timestamp(&deployment.ObjectMeta)
 	deployment.Spec = appsv1.DeploymentSpec{
 		Selector: &metav1.LabelSelector{
 			MatchLabels: fortioClientLabels,
@@ -61,7 +60,6 @@ func makeFortioDeployment(
 			},
 		},
 	}
-	timestamp(&deployment.Spec.Template.ObjectMeta)
 	return
 }
 
@@ -71,7 +69,6 @@ func makeFortioService() (service apiv1.Service) {
 	service.ObjectMeta.Name = "client"
 	service.ObjectMeta.Labels = fortioClientLabels
 	service.ObjectMeta.Annotations = prometheusScrapeAnnotations
-	timestamp(&service.ObjectMeta)
 	service.Spec.Ports = []apiv1.ServicePort{{Port: consts.ServicePort}}
 	service.Spec.Selector = fortioClientLabels
 	return
@@ -0,0 +1,177 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+const (
+	helmChartName        = "service-graph"
+	helmChartFileName    = "Chart.yaml"
+	helmValuesFileName   = "values.yaml"
+	helmTemplatesDirName = "templates"
+
+	serviceValuesKey = "service"
+	clientValuesKey  = "client"
+
+	// nodeSelectorPlaceholderKey marks a node selector to be replaced by its
+	// value in values.yaml once the template is marshalled.
+	nodeSelectorPlaceholderKey = "isotopeHelmNodeSelector"
+)
+
+var (
+	nodeSelectorPlaceholderRegexp = regexp.MustCompile(
+		`(?m)^( *)nodeSelector:\n *` + nodeSelectorPlaceholderKey + `: (\w+)$`)
+	replicasRegexp = regexp.MustCompile(`(?m)^( *)replicas: \d+$`)
+)
+
+type helmChart struct {
+	APIVersion  string `json:"apiVersion"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+type helmValues struct {
+	Service  helmWorkloadValues `json:"service"`
+	Client   helmWorkloadValues `json:"client"`
+	Replicas map[string]int32   `json:"replicas"`
+}
+
+type helmWorkloadValues struct {
+	Image        string            `json:"image"`
+	NodeSelector map[string]string `json:"nodeSelector"`
+}
+
+// ServiceGraphToHelmChart converts a ServiceGraph to a Helm chart with a
+// template per Kubernetes object. The images, node selectors and the number
+// of replicas of each service are exposed in values.yaml, defaulting to the
+// given arguments. It returns the contents of each file keyed by its path
+// relative to the chart.
+func ServiceGraphToHelmChart(
+	serviceGraph graph.ServiceGraph,
+	serviceNodeSelector map[string]string,
+	serviceImage string,
+	serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64,
+	clientNodeSelector map[string]string,
+	clientImage string,
+	environmentName string,
+	mesh MeshOptions) (map[string][]byte, error) {
+	objects, err := ServiceGraphToKubernetesObjects(
+		serviceGraph,
+		map[string]string{nodeSelectorPlaceholderKey: serviceValuesKey},
+		imageValue(serviceValuesKey),
+		serviceMaxIdleConnectionsPerHost,
+		serviceSeed,
+		map[string]string{nodeSelectorPlaceholderKey: clientValuesKey},
+		imageValue(clientValuesKey),
+		environmentName, mesh)
+	if err != nil {
+		return nil, err
+	}
+
+	values := helmValues{
+		Service: helmWorkloadValues{
+			Image:        serviceImage,
+			NodeSelector: serviceNodeSelector,
+		},
+		Client: helmWorkloadValues{
+			Image:        clientImage,
+			NodeSelector: clientNodeSelector,
+		},
+		Replicas: make(map[string]int32, len(serviceGraph.Services)),
+	}
+	for _, service := range serviceGraph.Services {
+		if len(service.Versions) == 0 {
+			values.Replicas[service.Name] = service.NumReplicas
+			continue
+		}
+		for _, version := range service.Versions {
+			values.Replicas[svc.VersionDeploymentName(service.Name, version.Name)] =
+				version.NumReplicas
+		}
+	}
+
+	files := make(map[string][]byte, len(objects)+2)
+	for _, object := range objects {
+		manifest, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		template := templatizeNodeSelector(string(manifest))
+		if _, ok := values.Replicas[object.GetName()]; ok &&
+			object.GetObjectKind().GroupVersionKind().Kind == "Deployment" {
+			template = templatizeReplicas(template, object.GetName())
+		}
+		fileName := path.Join(helmTemplatesDirName, objectFileName(object))
+		if _, ok := files[fileName]; ok {
+			return nil, ErrDuplicateFileName{FileName: fileName}
+		}
+		files[fileName] = []byte(template)
+	}
+
+	chartYAML, err := yaml.Marshal(helmChart{
+		APIVersion:  "v1",
+		Name:        helmChartName,
+		Version:     "0.1.0",
+		Description: "Isotope service graph",
+	})
+	if err != nil {
+		return nil, err
+	}
+	files[helmChartFileName] = chartYAML
+
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	files[helmValuesFileName] = valuesYAML
+
+	return files, nil
+}
+
+func imageValue(key string) string {
+	return fmt.Sprintf("{{ .Values.%s.image }}", key)
+}
+
+// templatizeNodeSelector replaces each placeholder node selector in manifest
+// with the node selector from values.yaml.
+func templatizeNodeSelector(manifest string) string {
+	return nodeSelectorPlaceholderRegexp.ReplaceAllStringFunc(
+		manifest, func(match string) string {
+			groups := nodeSelectorPlaceholderRegexp.FindStringSubmatch(match)
+			indent, key := groups[1], groups[2]
+			return fmt.Sprintf(
+				"%snodeSelector: {{- toYaml .Values.%s.nodeSelector | nindent %d }}",
+				indent, key, len(indent)+2)
+		})
+}
+
+// templatizeReplicas replaces the replicas of the Deployment of the service
+// in manifest with its value from values.yaml.
+func templatizeReplicas(manifest string, serviceName string) string {
+	return replicasRegexp.ReplaceAllString(
+		manifest,
+		fmt.Sprintf("${1}replicas: {{ index .Values.replicas %q }}", serviceName))
+}
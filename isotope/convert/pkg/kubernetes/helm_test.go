@@ -0,0 +1,193 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	appsv1 "k8s.io/api/apps/v1"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+// helmFuncs implements the Helm template functions used by the chart.
+var helmFuncs = template.FuncMap{
+	"toYaml": func(v interface{}) (string, error) {
+		b, err := yaml.Marshal(v)
+		return strings.TrimSuffix(string(b), "\n"), err
+	},
+	"nindent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		return "\n" + pad + strings.Replace(s, "\n", "\n"+pad, -1)
+	},
+}
+
+// renderHelmTemplate renders a template of the chart like Helm would with the
+// values of the chart.
+func renderHelmTemplate(t *testing.T, files map[string][]byte, name string) []byte {
+	t.Helper()
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(files[helmValuesFileName], &values); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := template.New(name).Funcs(helmFuncs).Parse(string(files[name]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, map[string]interface{}{"Values": values}); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestServiceGraphToHelmChart(t *testing.T) {
+	serviceGraph := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", NumReplicas: 2},
+		{Name: "b", NumReplicas: 1, Versions: []svc.Version{
+			{Name: "v1", NumReplicas: 3},
+			{Name: "v2", NumReplicas: 1},
+		}},
+	}}
+	serviceNodeSelector := map[string]string{"role": "service"}
+	clientNodeSelector := map[string]string{"role": "client"}
+	files, err := ServiceGraphToHelmChart(
+		serviceGraph, serviceNodeSelector, "isotope:1", 0, 0,
+		clientNodeSelector, "fortio:1", "NONE", MeshOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFiles := []string{
+		"Chart.yaml",
+		"templates/configmap-service-graph-config.yaml",
+		"templates/deployment-a.yaml",
+		"templates/deployment-b-v1.yaml",
+		"templates/deployment-b-v2.yaml",
+		"templates/deployment-client.yaml",
+		"templates/namespace-service-graph.yaml",
+		"templates/service-a.yaml",
+		"templates/service-b.yaml",
+		"templates/service-client.yaml",
+		"values.yaml",
+	}
+	actualFiles := make([]string, 0, len(files))
+	for name := range files {
+		actualFiles = append(actualFiles, name)
+	}
+	sort.Strings(actualFiles)
+	if !reflect.DeepEqual(expectedFiles, actualFiles) {
+		t.Errorf("expected %v; actual %v", expectedFiles, actualFiles)
+	}
+
+	expectedChart := helmChart{
+		APIVersion:  "v1",
+		Name:        "service-graph",
+		Version:     "0.1.0",
+		Description: "Isotope service graph",
+	}
+	var chart helmChart
+	if err := yaml.Unmarshal(files[helmChartFileName], &chart); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedChart, chart) {
+		t.Errorf("expected %v; actual %v", expectedChart, chart)
+	}
+
+	expectedValues := helmValues{
+		Service:  helmWorkloadValues{Image: "isotope:1", NodeSelector: serviceNodeSelector},
+		Client:   helmWorkloadValues{Image: "fortio:1", NodeSelector: clientNodeSelector},
+		Replicas: map[string]int32{"a": 2, "b-v1": 3, "b-v2": 1},
+	}
+	var values helmValues
+	if err := yaml.Unmarshal(files[helmValuesFileName], &values); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedValues, values) {
+		t.Errorf("expected %v; actual %v", expectedValues, values)
+	}
+
+	tests := []struct {
+		template     string
+		replicas     *int32
+		image        string
+		nodeSelector map[string]string
+	}{
+		{"templates/deployment-a.yaml", int32Ptr(2), "isotope:1", serviceNodeSelector},
+		{"templates/deployment-b-v1.yaml", int32Ptr(3), "isotope:1", serviceNodeSelector},
+		{"templates/deployment-b-v2.yaml", int32Ptr(1), "isotope:1", serviceNodeSelector},
+		{"templates/deployment-client.yaml", nil, "fortio:1", clientNodeSelector},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.template, func(t *testing.T) {
+			t.Parallel()
+
+			var deployment appsv1.Deployment
+			manifest := renderHelmTemplate(t, files, test.template)
+			if err := yaml.Unmarshal(manifest, &deployment); err != nil {
+				t.Fatalf("%v:\n%s", err, manifest)
+			}
+			if !reflect.DeepEqual(test.replicas, deployment.Spec.Replicas) {
+				t.Errorf("expected %v; actual %v", test.replicas, deployment.Spec.Replicas)
+			}
+			podSpec := deployment.Spec.Template.Spec
+			if image := podSpec.Containers[0].Image; test.image != image {
+				t.Errorf("expected %v; actual %v", test.image, image)
+			}
+			if !reflect.DeepEqual(test.nodeSelector, podSpec.NodeSelector) {
+				t.Errorf("expected %v; actual %v", test.nodeSelector, podSpec.NodeSelector)
+			}
+		})
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestTemplatizeNodeSelector(t *testing.T) {
+	input := `spec:
+  template:
+    spec:
+      nodeSelector:
+        isotopeHelmNodeSelector: service
+      volumes: []
+`
+	expected := `spec:
+  template:
+    spec:
+      nodeSelector: {{- toYaml .Values.service.nodeSelector | nindent 8 }}
+      volumes: []
+`
+	if actual := templatizeNodeSelector(input); expected != actual {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+}
+
+func TestTemplatizeReplicas(t *testing.T) {
+	input := "spec:\n  replicas: 3\n"
+	expected := "spec:\n  replicas: {{ index .Values.replicas \"svc-0\" }}\n"
+	if actual := templatizeReplicas(input, "svc-0"); expected != actual {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The types below mirror the subset of the Istio CRDs emitted by the
+// converter. They only exist to be marshalled to YAML, so they omit every
+// field the converter never sets.
+
+const (
+	securityAPIVersion   = "security.istio.io/v1beta1"
+	networkingAPIVersion = "networking.istio.io/v1alpha3"
+)
+
+// AuthorizationPolicy is a security.istio.io/v1beta1 AuthorizationPolicy.
+type AuthorizationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              AuthorizationPolicySpec `json:"spec"`
+}
+
+// AuthorizationPolicySpec is the spec of an AuthorizationPolicy.
+type AuthorizationPolicySpec struct {
+	Selector *WorkloadSelector `json:"selector,omitempty"`
+	Action   string            `json:"action,omitempty"`
+	Rules    []Rule            `json:"rules,omitempty"`
+}
+
+// WorkloadSelector selects the workloads a policy applies to.
+type WorkloadSelector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
+}
+
+// Rule matches requests from a list of sources to a list of operations.
+type Rule struct {
+	From []RuleFrom `json:"from,omitempty"`
+	To   []RuleTo   `json:"to,omitempty"`
+}
+
+// RuleFrom describes the source of a request.
+type RuleFrom struct {
+	Source Source `json:"source"`
+}
+
+// Source lists the peer identities a request may come from.
+type Source struct {
+	Principals []string `json:"principals,omitempty"`
+}
+
+// RuleTo describes the operation of a request.
+type RuleTo struct {
+	Operation Operation `json:"operation"`
+}
+
+// Operation lists the request attributes to match.
+type Operation struct {
+	Methods []string `json:"methods,omitempty"`
+	Paths   []string `json:"paths,omitempty"`
+}
+
+// PeerAuthentication is a security.istio.io/v1beta1 PeerAuthentication.
+type PeerAuthentication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              PeerAuthenticationSpec `json:"spec"`
+}
+
+// PeerAuthenticationSpec is the spec of a PeerAuthentication.
+type PeerAuthenticationSpec struct {
+	MTLS MutualTLS `json:"mtls"`
+}
+
+// MutualTLS sets the mutual TLS mode of a PeerAuthentication.
+type MutualTLS struct {
+	Mode string `json:"mode"`
+}
+
+// DestinationRule is a networking.istio.io/v1alpha3 DestinationRule.
+type DestinationRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              DestinationRuleSpec `json:"spec"`
+}
+
+// DestinationRuleSpec is the spec of a DestinationRule.
+type DestinationRuleSpec struct {
+	Host          string         `json:"host"`
+	TrafficPolicy *TrafficPolicy `json:"trafficPolicy,omitempty"`
+	Subsets       []Subset       `json:"subsets,omitempty"`
+}
+
+// Subset is a named set of the endpoints of a host, selected by labels.
+type Subset struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+}
+
+// TrafficPolicy is the traffic policy applied to a destination.
+type TrafficPolicy struct {
+	TLS *ClientTLSSettings `json:"tls,omitempty"`
+}
+
+// ClientTLSSettings sets the TLS mode used to connect to a destination.
+type ClientTLSSettings struct {
+	Mode string `json:"mode"`
+}
+
+// VirtualService is a networking.istio.io/v1alpha3 VirtualService.
+type VirtualService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VirtualServiceSpec `json:"spec"`
+}
+
+// VirtualServiceSpec is the spec of a VirtualService.
+type VirtualServiceSpec struct {
+	Hosts []string    `json:"hosts"`
+	HTTP  []HTTPRoute `json:"http,omitempty"`
+	TCP   []TCPRoute  `json:"tcp,omitempty"`
+}
+
+// TCPRoute is a single route of opaque TCP traffic of a VirtualService.
+type TCPRoute struct {
+	Match []L4MatchAttributes    `json:"match,omitempty"`
+	Route []HTTPRouteDestination `json:"route"`
+}
+
+// L4MatchAttributes matches connections by the labels of the calling
+// workload.
+type L4MatchAttributes struct {
+	SourceLabels map[string]string `json:"sourceLabels,omitempty"`
+}
+
+// HTTPRoute is a single route of a VirtualService.
+type HTTPRoute struct {
+	Name    string                 `json:"name,omitempty"`
+	Match   []HTTPMatchRequest     `json:"match,omitempty"`
+	Route   []HTTPRouteDestination `json:"route"`
+	Timeout string                 `json:"timeout,omitempty"`
+	Retries *HTTPRetry             `json:"retries,omitempty"`
+}
+
+// HTTPMatchRequest matches requests by the labels of the calling workload.
+type HTTPMatchRequest struct {
+	SourceLabels map[string]string `json:"sourceLabels,omitempty"`
+}
+
+// HTTPRouteDestination is a destination of a route.
+type HTTPRouteDestination struct {
+	Destination Destination `json:"destination"`
+	Weight      int32       `json:"weight,omitempty"`
+}
+
+// Destination is the host, and optionally the subset of it, traffic is routed
+// to.
+type Destination struct {
+	Host   string `json:"host"`
+	Subset string `json:"subset,omitempty"`
+}
+
+// HTTPRetry is the retry policy of a route.
+type HTTPRetry struct {
+	Attempts      int32  `json:"attempts"`
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+}
@@ -17,7 +17,7 @@ package kubernetes
 
 import (
 	"fmt"
-	"math/rand"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,15 +25,18 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"istio.io/tools/isotope/convert/pkg/consts"
 	"istio.io/tools/isotope/convert/pkg/graph"
 	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
 )
 
 const (
-	// ServiceGraphNamespace is the namespace all service graph related resources
-	// (i.e. ConfigMap, Services, and Deployments) will reside in.
+	// ServiceGraphNamespace is the namespace the service graph related resources
+	// (i.e. ConfigMap, Services, and Deployments) reside in, unless their
+	// services are placed in another namespace.
 	ServiceGraphNamespace = "service-graph"
 
 	numConfigMaps          = 1
@@ -41,6 +44,10 @@ const (
 
 	configVolume           = "config-volume"
 	serviceGraphConfigName = "service-graph-config"
+
+	// versionLabel is the label of the pods of a service version holding the
+	// name of the version.
+	versionLabel = "version"
 )
 
 var (
@@ -50,6 +57,60 @@ var (
 		"prometheus.io/scrape": "true"}
 )
 
+// MeshOptions controls the Istio resources generated for the service graph.
+// They are only generated when the environment is "ISTIO".
+type MeshOptions struct {
+	// PeerAuthenticationMode is the mutual TLS mode ("STRICT", "PERMISSIVE" or
+	// "DISABLE") set by a namespace-wide PeerAuthentication. No
+	// PeerAuthentication is generated if it is empty.
+	PeerAuthenticationMode string
+
+	// TrafficManagement generates a DestinationRule and a VirtualService for
+	// each service.
+	TrafficManagement bool
+
+	// RequestTimeout is the timeout of each edge in the VirtualServices. Zero
+	// means no timeout is set.
+	RequestTimeout time.Duration
+
+	// RequestRetries is the number of retry attempts of each edge in the
+	// VirtualServices. Zero means no retry policy is set.
+	RequestRetries int32
+}
+
+// peerAuthenticationModes are the mutual TLS modes a PeerAuthentication accepts.
+var peerAuthenticationModes = map[string]bool{
+	"STRICT":     true,
+	"PERMISSIVE": true,
+	"DISABLE":    true,
+}
+
+// validate returns an error if the options would generate invalid resources.
+func (mesh MeshOptions) validate() error {
+	if mesh.PeerAuthenticationMode != "" &&
+		!peerAuthenticationModes[mesh.PeerAuthenticationMode] {
+		return ErrInvalidPeerAuthenticationMode{mesh.PeerAuthenticationMode}
+	}
+	return nil
+}
+
+// ErrInvalidPeerAuthenticationMode is returned when the mutual TLS mode of the
+// PeerAuthentications is not one Istio accepts.
+type ErrInvalidPeerAuthenticationMode struct {
+	Mode string
+}
+
+func (e ErrInvalidPeerAuthenticationMode) Error() string {
+	return fmt.Sprintf(
+		`peer authentication mode "%s" must be STRICT, PERMISSIVE or DISABLE`, e.Mode)
+}
+
+// Object is a Kubernetes object generated for the service graph.
+type Object interface {
+	metav1.Object
+	GetObjectKind() schema.ObjectKind
+}
+
 // ServiceGraphToKubernetesManifests converts a ServiceGraph to Kubernetes
 // manifests.
 func ServiceGraphToKubernetesManifests(
@@ -57,82 +118,241 @@ func ServiceGraphToKubernetesManifests(
 	serviceNodeSelector map[string]string,
 	serviceImage string,
 	serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64,
+	clientNodeSelector map[string]string,
+	clientImage string,
+	environmentName string,
+	mesh MeshOptions) ([]byte, error) {
+	objects, err := ServiceGraphToKubernetesObjects(
+		serviceGraph, serviceNodeSelector, serviceImage,
+		serviceMaxIdleConnectionsPerHost, serviceSeed, clientNodeSelector, clientImage,
+		environmentName, mesh)
+	if err != nil {
+		return nil, err
+	}
+	return objectsToManifests(objects)
+}
+
+// ServiceGraphToKubernetesClusterManifests converts a ServiceGraph to the
+// Kubernetes manifests of each cluster its services are placed in, keyed by
+// the name of the cluster.
+func ServiceGraphToKubernetesClusterManifests(
+	serviceGraph graph.ServiceGraph,
+	serviceNodeSelector map[string]string,
+	serviceImage string,
+	serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64,
 	clientNodeSelector map[string]string,
 	clientImage string,
-	environmentName string) ([]byte, error) {
-	numServices := len(serviceGraph.Services)
-	numManifests := numManifestsPerService*numServices + numConfigMaps
-	manifests := make([]string, 0, numManifests)
+	environmentName string,
+	mesh MeshOptions) (map[string][]byte, error) {
+	objectsByCluster, err := ServiceGraphToKubernetesClusterObjects(
+		serviceGraph, serviceNodeSelector, serviceImage,
+		serviceMaxIdleConnectionsPerHost, serviceSeed, clientNodeSelector, clientImage,
+		environmentName, mesh)
+	if err != nil {
+		return nil, err
+	}
 
-	appendManifest := func(manifest interface{}) error {
-		yamlDoc, err := yaml.Marshal(manifest)
+	manifestsByCluster := make(map[string][]byte, len(objectsByCluster))
+	for cluster, objects := range objectsByCluster {
+		manifests, err := objectsToManifests(objects)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		manifests = append(manifests, string(yamlDoc))
-		return nil
+		manifestsByCluster[cluster] = manifests
 	}
+	return manifestsByCluster, nil
+}
 
-	namespace := makeServiceGraphNamespace()
-	if err := appendManifest(namespace); err != nil {
-		return nil, err
+func objectsToManifests(objects []Object) ([]byte, error) {
+	manifests := make([]string, 0, len(objects))
+	for _, object := range objects {
+		yamlDoc, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, string(yamlDoc))
 	}
 
-	configMap, err := makeConfigMap(serviceGraph)
+	yamlDocString := strings.Join(manifests, "---\n")
+	return []byte(yamlDocString), nil
+}
+
+// ServiceGraphToKubernetesObjects converts a ServiceGraph to the Kubernetes
+// objects to deploy, in the order they should be applied. All services are
+// deployed together, regardless of their cluster.
+func ServiceGraphToKubernetesObjects(
+	serviceGraph graph.ServiceGraph,
+	serviceNodeSelector map[string]string,
+	serviceImage string,
+	serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64,
+	clientNodeSelector map[string]string,
+	clientImage string,
+	environmentName string,
+	mesh MeshOptions) ([]Object, error) {
+	return makeObjects(
+		serviceGraph, placement{Deployed: serviceGraph.Services, WithClient: true},
+		serviceNodeSelector, serviceImage, serviceMaxIdleConnectionsPerHost,
+		serviceSeed, clientNodeSelector, clientImage, environmentName, mesh)
+}
+
+// ServiceGraphToKubernetesClusterObjects converts a ServiceGraph to the
+// Kubernetes objects to deploy in each cluster its services are placed in,
+// keyed by the name of the cluster. Besides its own services, each cluster
+// gets the Services (and, under Istio, the traffic rules) of the services it
+// calls in other clusters so that they resolve and route across the mesh.
+func ServiceGraphToKubernetesClusterObjects(
+	serviceGraph graph.ServiceGraph,
+	serviceNodeSelector map[string]string,
+	serviceImage string,
+	serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64,
+	clientNodeSelector map[string]string,
+	clientImage string,
+	environmentName string,
+	mesh MeshOptions) (map[string][]Object, error) {
+	placements, err := clusterPlacements(serviceGraph)
 	if err != nil {
 		return nil, err
 	}
-	if err := appendManifest(configMap); err != nil {
+
+	objectsByCluster := make(map[string][]Object, len(placements))
+	for cluster, p := range placements {
+		objects, err := makeObjects(
+			serviceGraph, p, serviceNodeSelector, serviceImage,
+			serviceMaxIdleConnectionsPerHost, serviceSeed, clientNodeSelector,
+			clientImage, environmentName, mesh)
+		if err != nil {
+			return nil, err
+		}
+		objectsByCluster[cluster] = objects
+	}
+	return objectsByCluster, nil
+}
+
+func makeObjects(
+	serviceGraph graph.ServiceGraph,
+	p placement,
+	serviceNodeSelector map[string]string,
+	serviceImage string,
+	serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64,
+	clientNodeSelector map[string]string,
+	clientImage string,
+	environmentName string,
+	mesh MeshOptions) ([]Object, error) {
+	if err := mesh.validate(); err != nil {
 		return nil, err
 	}
 
-	rand.Seed(time.Now().UTC().UnixNano())
-	hasRbacPolicy := false
+	numServices := len(p.Deployed)
+	numObjects := numManifestsPerService*numServices + numConfigMaps
+	objects := make([]Object, 0, numObjects)
+
+	isIstio := strings.EqualFold(environmentName, "ISTIO")
+
+	for _, name := range namespacesOf(append(p.Deployed, p.Remote...)) {
+		namespace := makeServiceGraphNamespace(name)
+		objects = append(objects, &namespace)
+	}
+
+	// Every namespace running services mounts its own copy of the graph.
+	deployedNamespaces := namespacesOf(p.Deployed)
+	for _, name := range deployedNamespaces {
+		configMap, err := makeConfigMap(serviceGraph, name)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, &configMap)
+	}
+
+	if isIstio && mesh.PeerAuthenticationMode != "" {
+		for _, name := range deployedNamespaces {
+			peerAuthentication := makePeerAuthentication(mesh.PeerAuthenticationMode, name)
+			objects = append(objects, &peerAuthentication)
+		}
+	}
+
+	servicesByName := make(map[string]svc.Service, len(serviceGraph.Services))
 	for _, service := range serviceGraph.Services {
-		k8sDeployment := makeDeployment(
-			service, serviceNodeSelector, serviceImage,
-			serviceMaxIdleConnectionsPerHost)
-		innerErr := appendManifest(k8sDeployment)
-		if innerErr != nil {
-			return nil, innerErr
+		servicesByName[service.Name] = service
+	}
+	callers := callersByService(serviceGraph)
+	for _, service := range p.Deployed {
+		// Under Istio each service runs as its own service account, so that
+		// policies can tell callers apart by their principal.
+		serviceAccountName := ""
+		if isIstio {
+			serviceAccountName = service.Name
+			k8sServiceAccount := makeServiceAccount(service)
+			objects = append(objects, &k8sServiceAccount)
+		}
+
+		if len(service.Versions) == 0 {
+			k8sDeployment := makeDeployment(
+				service, serviceNodeSelector, serviceImage, serviceMaxIdleConnectionsPerHost,
+				svc.DeploymentSeed(serviceSeed, service.Name), serviceAccountName)
+			objects = append(objects, &k8sDeployment)
+		} else {
+			k8sDeployments := makeVersionDeployments(
+				service, serviceNodeSelector, serviceImage,
+				serviceMaxIdleConnectionsPerHost, serviceSeed, serviceAccountName)
+			for i := range k8sDeployments {
+				objects = append(objects, &k8sDeployments[i])
+			}
 		}
 
 		k8sService := makeService(service)
-		innerErr = appendManifest(k8sService)
-		if innerErr != nil {
-			return nil, innerErr
+		objects = append(objects, &k8sService)
+
+		if !isIstio {
+			continue
 		}
 
-		// Only generates the RBAC rules when Istio is installed.
-		if strings.EqualFold(environmentName, "ISTIO") && service.NumRbacPolicies > 0 {
-			hasRbacPolicy = true
-			var i int32
-			// Generates random RBAC rules for the service.
-			for i = 0; i < service.NumRbacPolicies; i++ {
-				manifests = append(manifests, generateRbacPolicy(service, false /* allowAll */))
-			}
-			// Generates "allow-all" RBAC rule for the service.
-			manifests = append(manifests, generateRbacPolicy(service, true /* allowAll */))
+		// Only generates the authorization policies when Istio is installed.
+		callerServices := make([]svc.Service, 0, len(callers[service.Name]))
+		for _, caller := range callers[service.Name] {
+			callerServices = append(callerServices, servicesByName[caller])
+		}
+		policies := makeAuthorizationPolicies(service, callerServices)
+		for i := range policies {
+			objects = append(objects, &policies[i])
 		}
-	}
 
-	fortioDeployment := makeFortioDeployment(
-		clientNodeSelector, clientImage)
-	if err := appendManifest(fortioDeployment); err != nil {
-		return nil, err
+		if mesh.TrafficManagement {
+			destinationRule := makeDestinationRule(service, mesh)
+			virtualService := makeVirtualService(
+				service, callers[service.Name], mesh)
+			objects = append(objects, &destinationRule, &virtualService)
+		}
 	}
 
-	fortioService := makeFortioService()
-	if err := appendManifest(fortioService); err != nil {
-		return nil, err
+	for _, service := range p.Remote {
+		k8sService := makeService(service)
+		objects = append(objects, &k8sService)
+
+		// The sidecars of the callers apply the traffic rules, so they are
+		// needed in the callers' cluster.
+		if isIstio && mesh.TrafficManagement {
+			destinationRule := makeDestinationRule(service, mesh)
+			virtualService := makeVirtualService(
+				service, callers[service.Name], mesh)
+			objects = append(objects, &destinationRule, &virtualService)
+		}
 	}
 
-	if hasRbacPolicy {
-		manifests = append(manifests, generateRbacConfig())
+	if p.WithClient {
+		fortioDeployment := makeFortioDeployment(
+			clientNodeSelector, clientImage)
+		objects = append(objects, &fortioDeployment)
+
+		fortioService := makeFortioService()
+		objects = append(objects, &fortioService)
 	}
 
-	yamlDocString := strings.Join(manifests, "---\n")
-	return []byte(yamlDocString), nil
+	return objects, nil
 }
 
 func combineLabels(a, b map[string]string) map[string]string {
@@ -146,17 +366,16 @@ func combineLabels(a, b map[string]string) map[string]string {
 	return c
 }
 
-func makeServiceGraphNamespace() (namespace apiv1.Namespace) {
+func makeServiceGraphNamespace(name string) (namespace apiv1.Namespace) {
 	namespace.APIVersion = "v1"
 	namespace.Kind = "Namespace"
-	namespace.ObjectMeta.Name = consts.ServiceGraphNamespace
+	namespace.ObjectMeta.Name = name
 	namespace.ObjectMeta.Labels = map[string]string{"istio-injection": "enabled"}
-	timestamp(&namespace.ObjectMeta)
 	return
 }
 
 func makeConfigMap(
-	graph graph.ServiceGraph) (configMap apiv1.ConfigMap, err error) {
+	graph graph.ServiceGraph, namespace string) (configMap apiv1.ConfigMap, err error) {
 	graphYAMLBytes, err := yaml.Marshal(graph)
 	if err != nil {
 		return
@@ -164,37 +383,47 @@ func makeConfigMap(
 	configMap.APIVersion = "v1"
 	configMap.Kind = "ConfigMap"
 	configMap.ObjectMeta.Name = serviceGraphConfigName
-	configMap.ObjectMeta.Namespace = ServiceGraphNamespace
+	configMap.ObjectMeta.Namespace = namespace
 	configMap.ObjectMeta.Labels = serviceGraphAppLabels
-	timestamp(&configMap.ObjectMeta)
 	configMap.Data = map[string]string{
 		consts.ServiceGraphConfigMapKey: string(graphYAMLBytes),
 	}
 	return
 }
 
+func makeServiceAccount(service svc.Service) (serviceAccount apiv1.ServiceAccount) {
+	serviceAccount.APIVersion = "v1"
+	serviceAccount.Kind = "ServiceAccount"
+	serviceAccount.ObjectMeta.Name = service.Name
+	serviceAccount.ObjectMeta.Namespace = service.EffectiveNamespace()
+	serviceAccount.ObjectMeta.Labels = serviceGraphAppLabels
+	return
+}
+
 func makeService(service svc.Service) (k8sService apiv1.Service) {
 	k8sService.APIVersion = "v1"
 	k8sService.Kind = "Service"
 	k8sService.ObjectMeta.Name = service.Name
-	k8sService.ObjectMeta.Namespace = ServiceGraphNamespace
+	k8sService.ObjectMeta.Namespace = service.EffectiveNamespace()
 	k8sService.ObjectMeta.Labels = serviceGraphAppLabels
-	timestamp(&k8sService.ObjectMeta)
-	k8sService.Spec.Ports = []apiv1.ServicePort{{Port: consts.ServicePort, Name: consts.ServicePortName}}
+	portName := consts.ServicePortName
+	if service.Type == svctype.ServiceTCP {
+		portName = consts.TCPServicePortName
+	}
+	k8sService.Spec.Ports = []apiv1.ServicePort{{Port: consts.ServicePort, Name: portName}}
 	k8sService.Spec.Selector = map[string]string{"name": service.Name}
 	return
 }
 
 func makeDeployment(
 	service svc.Service, nodeSelector map[string]string,
-	serviceImage string, serviceMaxIdleConnectionsPerHost int) (
-	k8sDeployment appsv1.Deployment) {
+	serviceImage string, serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64, serviceAccountName string) (k8sDeployment appsv1.Deployment) {
 	k8sDeployment.APIVersion = "apps/v1"
 	k8sDeployment.Kind = "Deployment"
 	k8sDeployment.ObjectMeta.Name = service.Name
-	k8sDeployment.ObjectMeta.Namespace = ServiceGraphNamespace
+	k8sDeployment.ObjectMeta.Namespace = service.EffectiveNamespace()
 	k8sDeployment.ObjectMeta.Labels = serviceGraphAppLabels
-	timestamp(&k8sDeployment.ObjectMeta)
 	k8sDeployment.Spec = appsv1.DeploymentSpec{
 		Replicas: &service.NumReplicas,
 		Selector: &metav1.LabelSelector{
@@ -212,16 +441,13 @@ func makeDeployment(
 				Annotations: prometheusScrapeAnnotations,
 			},
 			Spec: apiv1.PodSpec{
-				NodeSelector: nodeSelector,
+				ServiceAccountName: serviceAccountName,
+				NodeSelector:       nodeSelector,
 				Containers: []apiv1.Container{
 					{
 						Name:  consts.ServiceContainerName,
 						Image: serviceImage,
-						Args: []string{
-							fmt.Sprintf(
-								"--max-idle-connections-per-host=%v",
-								serviceMaxIdleConnectionsPerHost),
-						},
+						Args:  makeServiceArgs(serviceMaxIdleConnectionsPerHost, serviceSeed),
 						Env: []apiv1.EnvVar{
 							{Name: consts.ServiceNameEnvKey, Value: service.Name},
 						},
@@ -259,10 +485,63 @@ func makeDeployment(
 			},
 		},
 	}
-	timestamp(&k8sDeployment.Spec.Template.ObjectMeta)
+	if service.Type == svctype.ServiceTCP {
+		// Prometheus scrapes TCP services on their separate metrics port.
+		podSpec := &k8sDeployment.Spec.Template
+		podSpec.ObjectMeta.Annotations = combineLabels(
+			prometheusScrapeAnnotations,
+			map[string]string{"prometheus.io/port": strconv.Itoa(consts.ServiceMetricsPort)})
+		container := &podSpec.Spec.Containers[0]
+		container.Ports = append(container.Ports, apiv1.ContainerPort{
+			ContainerPort: consts.ServiceMetricsPort,
+		})
+	}
 	return
 }
 
-func timestamp(objectMeta *metav1.ObjectMeta) {
-	objectMeta.CreationTimestamp = metav1.Time{Time: time.Now()}
+// makeVersionDeployments makes a Deployment per version of the service. The
+// pods of each version are labelled with its name and run the service as that
+// version; all of them back the service's Kubernetes Service.
+func makeVersionDeployments(
+	service svc.Service, nodeSelector map[string]string,
+	serviceImage string, serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64, serviceAccountName string) []appsv1.Deployment {
+	k8sDeployments := make([]appsv1.Deployment, 0, len(service.Versions))
+	for _, version := range service.Versions {
+		name := svc.VersionDeploymentName(service.Name, version.Name)
+		versionService, _ := service.WithVersion(version.Name)
+		k8sDeployment := makeDeployment(
+			versionService, nodeSelector, serviceImage, serviceMaxIdleConnectionsPerHost,
+			svc.DeploymentSeed(serviceSeed, name), serviceAccountName)
+
+		labels := map[string]string{
+			"name":       service.Name,
+			versionLabel: version.Name,
+		}
+		k8sDeployment.ObjectMeta.Name = name
+		k8sDeployment.Spec.Selector.MatchLabels = labels
+		k8sDeployment.Spec.Template.ObjectMeta.Labels = combineLabels(
+			serviceGraphNodeLabels, labels)
+		container := &k8sDeployment.Spec.Template.Spec.Containers[0]
+		container.Env = append(container.Env, apiv1.EnvVar{
+			Name: consts.ServiceVersionEnvKey, Value: version.Name,
+		})
+		k8sDeployments = append(k8sDeployments, k8sDeployment)
+	}
+	return k8sDeployments
+}
+
+// makeServiceArgs returns the arguments of the service container. The seed is
+// only passed when set, leaving the services to seed themselves otherwise.
+func makeServiceArgs(
+	serviceMaxIdleConnectionsPerHost int, serviceSeed int64) []string {
+	args := []string{
+		fmt.Sprintf(
+			"--max-idle-connections-per-host=%v",
+			serviceMaxIdleConnectionsPerHost),
+	}
+	if serviceSeed != 0 {
+		args = append(args, fmt.Sprintf("--seed=%v", serviceSeed))
+	}
+	return args
 }
@@ -0,0 +1,132 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/")
+
+// summarizedTopologies are the example topologies which manifests are too large
+// to be kept in full, and are checked against their summary instead.
+var summarizedTopologies = map[string]bool{"1000-svc_5000-end": true}
+
+// manifestsSummary is the summary of manifests compared instead of the full
+// manifests of large topologies.
+type manifestsSummary struct {
+	// Kinds counts the objects of each kind.
+	Kinds map[string]int `json:"kinds"`
+	// SHA256 is the digest of the manifests.
+	SHA256 string `json:"sha256"`
+}
+
+// summarize returns the summary of manifests as YAML.
+func summarize(manifests []byte) ([]byte, error) {
+	summary := manifestsSummary{Kinds: map[string]int{}}
+	for _, doc := range bytes.Split(manifests, []byte("\n---\n")) {
+		var object struct {
+			Kind string `json:"kind"`
+		}
+		if err := yaml.Unmarshal(doc, &object); err != nil {
+			return nil, err
+		}
+		if object.Kind != "" {
+			summary.Kinds[object.Kind]++
+		}
+	}
+	digest := sha256.Sum256(manifests)
+	summary.SHA256 = hex.EncodeToString(digest[:])
+	return yaml.Marshal(summary)
+}
+
+// TestServiceGraphToKubernetesManifests_Golden checks the manifests of every
+// example topology against testdata/*.golden.yaml, or the summary of the
+// manifests of the large ones against testdata/*.golden.summary.yaml. Run with
+// -update to regenerate the golden files.
+func TestServiceGraphToKubernetesManifests_Golden(t *testing.T) {
+	topologyPaths, err := filepath.Glob(
+		filepath.Join("..", "..", "..", "example-topologies", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topologyPaths) == 0 {
+		t.Fatal("no example topologies found")
+	}
+
+	for _, topologyPath := range topologyPaths {
+		topologyPath := topologyPath
+		name := strings.TrimSuffix(filepath.Base(topologyPath), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			yamlContents, err := ioutil.ReadFile(topologyPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var serviceGraph graph.ServiceGraph
+			if err := yaml.Unmarshal(yamlContents, &serviceGraph); err != nil {
+				t.Fatal(err)
+			}
+
+			manifests, err := ServiceGraphToKubernetesManifests(
+				serviceGraph, map[string]string{"role": "service"},
+				"istio/isotope", 32, 1, map[string]string{"role": "client"},
+				"fortio/fortio", "ISTIO",
+				MeshOptions{
+					PeerAuthenticationMode: "STRICT",
+					TrafficManagement:      true,
+					RequestTimeout:         time.Second,
+					RequestRetries:         2,
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			goldenPath := filepath.Join("testdata", name+".golden.yaml")
+			if summarizedTopologies[name] {
+				goldenPath = filepath.Join("testdata", name+".golden.summary.yaml")
+				if manifests, err = summarize(manifests); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if *update {
+				if err := ioutil.WriteFile(goldenPath, manifests, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			golden, err := ioutil.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(golden, manifests) {
+				t.Errorf("manifests for %s differ from %s; run with -update to regenerate",
+					topologyPath, goldenPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+)
+
+const kustomizationFileName = "kustomization.yaml"
+
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
+}
+
+// ServiceGraphToKustomization converts a ServiceGraph to a Kustomize base: one
+// file per Kubernetes object plus a kustomization.yaml listing them. It
+// returns the contents of each file keyed by its path relative to the base.
+func ServiceGraphToKustomization(
+	serviceGraph graph.ServiceGraph,
+	serviceNodeSelector map[string]string,
+	serviceImage string,
+	serviceMaxIdleConnectionsPerHost int,
+	serviceSeed int64,
+	clientNodeSelector map[string]string,
+	clientImage string,
+	environmentName string,
+	mesh MeshOptions) (map[string][]byte, error) {
+	objects, err := ServiceGraphToKubernetesObjects(
+		serviceGraph, serviceNodeSelector, serviceImage,
+		serviceMaxIdleConnectionsPerHost, serviceSeed, clientNodeSelector, clientImage,
+		environmentName, mesh)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(objects)+1)
+	k := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  make([]string, 0, len(objects)),
+	}
+	for _, object := range objects {
+		fileName := objectFileName(object)
+		if _, ok := files[fileName]; ok {
+			return nil, ErrDuplicateFileName{FileName: fileName}
+		}
+		manifest, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		files[fileName] = manifest
+		k.Resources = append(k.Resources, fileName)
+	}
+
+	kustomizationYAML, err := yaml.Marshal(k)
+	if err != nil {
+		return nil, err
+	}
+	files[kustomizationFileName] = kustomizationYAML
+	return files, nil
+}
+
+// objectFileName returns the name of the file holding object's manifest,
+// which is unique for each kind, namespace and name: <kind>-<name>.yaml, in a
+// directory named after the namespace unless it is the service graph
+// namespace. Kinds have no dashes, and names no slashes, so distinct objects
+// never share a file.
+func objectFileName(object Object) string {
+	kind := strings.ToLower(object.GetObjectKind().GroupVersionKind().Kind)
+	fileName := fmt.Sprintf("%s-%s.yaml", kind, object.GetName())
+	namespace := object.GetNamespace()
+	if namespace == "" || namespace == ServiceGraphNamespace {
+		return fileName
+	}
+	return path.Join(namespace, fileName)
+}
+
+// ErrDuplicateFileName is returned when two objects would be written to the
+// same file, e.g. two objects of the same kind, namespace and name.
+type ErrDuplicateFileName struct {
+	FileName string
+}
+
+func (e ErrDuplicateFileName) Error() string {
+	return fmt.Sprintf(`two objects would both be written to "%s"`, e.FileName)
+}
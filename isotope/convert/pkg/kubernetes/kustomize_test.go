@@ -0,0 +1,103 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+func TestServiceGraphToKustomization(t *testing.T) {
+	files, err := ServiceGraphToKustomization(
+		serviceGraphWithEdges, nil, "", 0, 0, nil, "", "NONE", MeshOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var k kustomization
+	if err := yaml.Unmarshal(files[kustomizationFileName], &k); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"configmap-service-graph-config.yaml",
+		"deployment-a.yaml",
+		"deployment-b.yaml",
+		"deployment-c.yaml",
+		"deployment-client.yaml",
+		"namespace-service-graph.yaml",
+		"service-a.yaml",
+		"service-b.yaml",
+		"service-c.yaml",
+		"service-client.yaml",
+	}
+	actual := append([]string{}, k.Resources...)
+	sort.Strings(actual)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+	for _, resource := range k.Resources {
+		if _, ok := files[resource]; !ok {
+			t.Errorf("missing file for resource %s", resource)
+		}
+	}
+}
+
+func TestObjectFileName(t *testing.T) {
+	service := func(namespace, name string) Object {
+		return &apiv1.Service{
+			TypeMeta:   metav1.TypeMeta{Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		}
+	}
+	tests := []struct {
+		object   Object
+		expected string
+	}{
+		{service(ServiceGraphNamespace, "a-b"), "service-a-b.yaml"},
+		{service("a", "b"), "a/service-b.yaml"},
+		{service("a-b", "c"), "a-b/service-c.yaml"},
+		{&apiv1.Namespace{
+			TypeMeta:   metav1.TypeMeta{Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		}, "namespace-a.yaml"},
+	}
+	for _, test := range tests {
+		if actual := objectFileName(test.object); actual != test.expected {
+			t.Errorf("expected %v; actual %v", test.expected, actual)
+		}
+	}
+}
+
+func TestServiceGraphToKustomization_DuplicateFileName(t *testing.T) {
+	serviceGraph := graph.ServiceGraph{Services: []svc.Service{{Name: "client"}}}
+	expected := ErrDuplicateFileName{FileName: "deployment-client.yaml"}
+	_, err := ServiceGraphToKustomization(serviceGraph, nil, "", 0, 0, nil, "", "NONE", MeshOptions{})
+	if err != expected {
+		t.Errorf("expected %v; actual %v", expected, err)
+	}
+	expected = ErrDuplicateFileName{FileName: "templates/deployment-client.yaml"}
+	_, err = ServiceGraphToHelmChart(serviceGraph, nil, "", 0, 0, nil, "", "NONE", MeshOptions{})
+	if err != expected {
+		t.Errorf("expected %v; actual %v", expected, err)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+// placement selects the services of a service graph which make up a cluster.
+type placement struct {
+	// Deployed are the services whose workloads run in the cluster.
+	Deployed []svc.Service
+
+	// Remote are the services called from the cluster which run in another.
+	Remote []svc.Service
+
+	// WithClient deploys the load testing client in the cluster.
+	WithClient bool
+}
+
+// clusterPlacements returns the placement of each cluster of the service
+// graph, keyed by its name. The load testing client runs in each cluster with
+// an entrypoint.
+func clusterPlacements(serviceGraph graph.ServiceGraph) (map[string]placement, error) {
+	placements := map[string]placement{}
+	for _, service := range serviceGraph.Services {
+		p := placements[service.Cluster]
+		p.Deployed = append(p.Deployed, service)
+		p.WithClient = p.WithClient || service.IsEntrypoint
+		placements[service.Cluster] = p
+	}
+	if _, ok := placements[""]; ok && len(placements) > 1 {
+		for _, service := range serviceGraph.Services {
+			if service.Cluster == "" {
+				return nil, ErrUnplacedService{service.Name}
+			}
+		}
+	}
+
+	servicesByName := make(map[string]svc.Service, len(serviceGraph.Services))
+	for _, service := range serviceGraph.Services {
+		servicesByName[service.Name] = service
+	}
+	for cluster, p := range placements {
+		remote := map[string]bool{}
+		for _, service := range p.Deployed {
+			for _, s := range service.Scripts() {
+				for _, callee := range calledServices(s) {
+					if servicesByName[callee].Cluster != cluster {
+						remote[callee] = true
+					}
+				}
+			}
+		}
+		// Keeps the order of the service graph for stable output.
+		for _, service := range serviceGraph.Services {
+			if remote[service.Name] {
+				p.Remote = append(p.Remote, service)
+			}
+		}
+		placements[cluster] = p
+	}
+	return placements, nil
+}
+
+// namespacesOf returns the sorted namespaces of services.
+func namespacesOf(services []svc.Service) []string {
+	set := map[string]bool{}
+	for _, service := range services {
+		set[service.EffectiveNamespace()] = true
+	}
+	namespaces := make([]string, 0, len(set))
+	for namespace := range set {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// ErrUnplacedService is returned when converting a service graph per cluster
+// where some services are placed in a cluster but the service does not.
+type ErrUnplacedService struct {
+	Service string
+}
+
+func (e ErrUnplacedService) Error() string {
+	return fmt.Sprintf(
+		`service "%s" must be placed in a cluster, like the other services`, e.Service)
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+var serviceGraphWithClusters = graph.ServiceGraph{Services: []svc.Service{
+	{Name: "a", Cluster: "east", Namespace: "storage"},
+	{Name: "b", Cluster: "west", Script: script.Script{
+		script.RequestCommand{ServiceName: "a"},
+	}},
+	{Name: "c", Cluster: "west", IsEntrypoint: true, Script: script.Script{
+		script.RequestCommand{ServiceName: "b"},
+	}},
+}}
+
+func TestServiceGraphToKubernetesClusterObjects(t *testing.T) {
+	objectsByCluster, err := ServiceGraphToKubernetesClusterObjects(
+		serviceGraphWithClusters, nil, "", 0, 0, nil, "", "ISTIO",
+		MeshOptions{PeerAuthenticationMode: "STRICT", TrafficManagement: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"east": {
+			"Namespace//storage",
+			"ConfigMap/storage/service-graph-config",
+			"PeerAuthentication/storage/default",
+			"ServiceAccount/storage/a",
+			"Deployment/storage/a",
+			"Service/storage/a",
+			"DestinationRule/storage/a",
+			"VirtualService/storage/a",
+		},
+		"west": {
+			"Namespace//service-graph",
+			"Namespace//storage",
+			"ConfigMap/service-graph/service-graph-config",
+			"PeerAuthentication/service-graph/default",
+			"ServiceAccount/service-graph/b",
+			"Deployment/service-graph/b",
+			"Service/service-graph/b",
+			"DestinationRule/service-graph/b",
+			"VirtualService/service-graph/b",
+			"ServiceAccount/service-graph/c",
+			"Deployment/service-graph/c",
+			"Service/service-graph/c",
+			"DestinationRule/service-graph/c",
+			"VirtualService/service-graph/c",
+			"Service/storage/a",
+			"DestinationRule/storage/a",
+			"VirtualService/storage/a",
+			"Deployment//client",
+			"Service//client",
+		},
+	}
+	actual := make(map[string][]string, len(objectsByCluster))
+	for cluster, objects := range objectsByCluster {
+		for _, object := range objects {
+			actual[cluster] = append(actual[cluster], fmt.Sprintf("%s/%s/%s",
+				object.GetObjectKind().GroupVersionKind().Kind,
+				object.GetNamespace(), object.GetName()))
+		}
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+}
+
+func TestServiceGraphToKubernetesClusterObjects_UnplacedService(t *testing.T) {
+	serviceGraph := graph.ServiceGraph{Services: []svc.Service{
+		{Name: "a", Cluster: "east"},
+		{Name: "b"},
+	}}
+	expected := ErrUnplacedService{"b"}
+	_, err := ServiceGraphToKubernetesClusterObjects(
+		serviceGraph, nil, "", 0, 0, nil, "", "NONE", MeshOptions{})
+	if err != expected {
+		t.Errorf("expected %v; actual %v", expected, err)
+	}
+}
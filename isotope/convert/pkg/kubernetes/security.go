@@ -0,0 +1,135 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+const (
+	trustDomain = "cluster.local"
+	metricsPath = "/metrics"
+)
+
+// makeAuthorizationPolicies generates service.NumRbacPolicies ALLOW policies
+// for the service. Each only admits the services which actually call it (or
+// everyone, for entrypoints) plus Prometheus scraping its metrics.
+func makeAuthorizationPolicies(
+	service svc.Service, callers []svc.Service) []AuthorizationPolicy {
+	var rules []Rule
+	if service.IsEntrypoint {
+		// Entrypoints represent public services, so any source may call them.
+		rules = append(rules, Rule{})
+	} else if len(callers) > 0 {
+		principals := make([]string, 0, len(callers))
+		for _, caller := range callers {
+			principals = append(principals, principal(caller))
+		}
+		rules = append(rules, Rule{
+			From: []RuleFrom{{Source: Source{Principals: principals}}},
+		})
+	}
+	rules = append(rules, Rule{
+		To: []RuleTo{{Operation: Operation{
+			Methods: []string{"GET"},
+			Paths:   []string{metricsPath},
+		}}},
+	})
+
+	policies := make([]AuthorizationPolicy, 0, service.NumRbacPolicies)
+	var i int32
+	for i = 0; i < service.NumRbacPolicies; i++ {
+		var policy AuthorizationPolicy
+		policy.APIVersion = securityAPIVersion
+		policy.Kind = "AuthorizationPolicy"
+		policy.ObjectMeta.Name = fmt.Sprintf("%s-%d", service.Name, i)
+		policy.ObjectMeta.Namespace = service.EffectiveNamespace()
+		policy.ObjectMeta.Labels = serviceGraphAppLabels
+		policy.Spec = AuthorizationPolicySpec{
+			Selector: &WorkloadSelector{
+				MatchLabels: map[string]string{"name": service.Name},
+			},
+			Action: "ALLOW",
+			Rules:  rules,
+		}
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+// makePeerAuthentication sets the mutual TLS mode for a whole namespace of the
+// service graph.
+func makePeerAuthentication(
+	mode string, namespace string) (peerAuthentication PeerAuthentication) {
+	peerAuthentication.APIVersion = securityAPIVersion
+	peerAuthentication.Kind = "PeerAuthentication"
+	peerAuthentication.ObjectMeta.Name = "default"
+	peerAuthentication.ObjectMeta.Namespace = namespace
+	peerAuthentication.ObjectMeta.Labels = serviceGraphAppLabels
+	peerAuthentication.Spec.MTLS.Mode = mode
+	return
+}
+
+// principal returns the SPIFFE identity of the workloads of the service.
+func principal(service svc.Service) string {
+	return fmt.Sprintf(
+		"%s/ns/%s/sa/%s", trustDomain, service.EffectiveNamespace(), service.Name)
+}
+
+// callersByService maps the name of each service to the sorted names of the
+// services whose scripts call it.
+func callersByService(serviceGraph graph.ServiceGraph) map[string][]string {
+	callerSets := make(map[string]map[string]bool, len(serviceGraph.Services))
+	for _, service := range serviceGraph.Services {
+		for _, s := range service.Scripts() {
+			for _, callee := range calledServices(s) {
+				if callerSets[callee] == nil {
+					callerSets[callee] = map[string]bool{}
+				}
+				callerSets[callee][service.Name] = true
+			}
+		}
+	}
+
+	callers := make(map[string][]string, len(callerSets))
+	for callee, set := range callerSets {
+		names := make([]string, 0, len(set))
+		for name := range set {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		callers[callee] = names
+	}
+	return callers
+}
+
+// calledServices returns the name of every service called in cmds, including
+// calls nested in concurrent commands.
+func calledServices(cmds []script.Command) (names []string) {
+	for _, cmd := range cmds {
+		switch cmd := cmd.(type) {
+		case script.RequestCommand:
+			names = append(names, cmd.ServiceName)
+		case script.ConcurrentCommand:
+			names = append(names, calledServices(cmd)...)
+		}
+	}
+	return
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+var serviceGraphWithEdges = graph.ServiceGraph{Services: []svc.Service{
+	{Name: "a"},
+	{Name: "b", Script: script.Script{
+		script.RequestCommand{ServiceName: "a"},
+	}},
+	{Name: "c", IsEntrypoint: true, Script: script.Script{
+		script.ConcurrentCommand{
+			script.RequestCommand{ServiceName: "b"},
+			script.RequestCommand{ServiceName: "a"},
+		},
+		script.RequestCommand{ServiceName: "a"},
+	}},
+}}
+
+func TestCallersByService(t *testing.T) {
+	expected := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+	}
+	actual := callersByService(serviceGraphWithEdges)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+}
+
+func TestMakeAuthorizationPolicies(t *testing.T) {
+	metricsRule := Rule{
+		To: []RuleTo{{Operation: Operation{
+			Methods: []string{"GET"},
+			Paths:   []string{"/metrics"},
+		}}},
+	}
+
+	tests := []struct {
+		service svc.Service
+		callers []svc.Service
+		names   []string
+		rules   []Rule
+	}{
+		{
+			svc.Service{Name: "a", NumRbacPolicies: 2},
+			[]svc.Service{{Name: "b"}, {Name: "c", Namespace: "shop"}},
+			[]string{"a-0", "a-1"},
+			[]Rule{
+				{From: []RuleFrom{{Source: Source{Principals: []string{
+					"cluster.local/ns/service-graph/sa/b",
+					"cluster.local/ns/shop/sa/c",
+				}}}}},
+				metricsRule,
+			},
+		},
+		{
+			svc.Service{Name: "c", IsEntrypoint: true, NumRbacPolicies: 1},
+			nil,
+			[]string{"c-0"},
+			[]Rule{{}, metricsRule},
+		},
+		{
+			svc.Service{Name: "d"},
+			nil,
+			[]string{},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.service.Name, func(t *testing.T) {
+			t.Parallel()
+
+			policies := makeAuthorizationPolicies(test.service, test.callers)
+			names := make([]string, 0, len(policies))
+			for _, policy := range policies {
+				names = append(names, policy.Name)
+				if !reflect.DeepEqual(test.rules, policy.Spec.Rules) {
+					t.Errorf("expected %v; actual %v", test.rules, policy.Spec.Rules)
+				}
+				selected := policy.Spec.Selector.MatchLabels["name"]
+				if selected != test.service.Name {
+					t.Errorf("expected %v; actual %v", test.service.Name, selected)
+				}
+			}
+			if !reflect.DeepEqual(test.names, names) {
+				t.Errorf("expected %v; actual %v", test.names, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
+)
+
+// makeDestinationRule defines a subset for each version of the service. Unless
+// mutual TLS is disabled, the sidecars of the callers connect with Istio
+// mutual TLS.
+func makeDestinationRule(
+	service svc.Service, mesh MeshOptions) (rule DestinationRule) {
+	rule.APIVersion = networkingAPIVersion
+	rule.Kind = "DestinationRule"
+	rule.ObjectMeta.Name = service.Name
+	rule.ObjectMeta.Namespace = service.EffectiveNamespace()
+	rule.ObjectMeta.Labels = serviceGraphAppLabels
+	rule.Spec = DestinationRuleSpec{Host: service.FQDN()}
+	if mesh.PeerAuthenticationMode != "DISABLE" {
+		rule.Spec.TrafficPolicy = &TrafficPolicy{
+			TLS: &ClientTLSSettings{Mode: "ISTIO_MUTUAL"},
+		}
+	}
+	for _, version := range service.Versions {
+		rule.Spec.Subsets = append(rule.Spec.Subsets, Subset{
+			Name:   version.Name,
+			Labels: map[string]string{versionLabel: version.Name},
+		})
+	}
+	return
+}
+
+// makeVirtualService routes each edge from callers to the service separately,
+// so every edge carries its own timeout and retry policy. Requests from
+// anywhere else (e.g. the load testing client) use the final, unmatched route.
+func makeVirtualService(
+	service svc.Service, callers []string, mesh MeshOptions) (
+	virtualService VirtualService) {
+	virtualService.APIVersion = networkingAPIVersion
+	virtualService.Kind = "VirtualService"
+	virtualService.ObjectMeta.Name = service.Name
+	virtualService.ObjectMeta.Namespace = service.EffectiveNamespace()
+	virtualService.ObjectMeta.Labels = serviceGraphAppLabels
+
+	host := service.FQDN()
+	destinations := []HTTPRouteDestination{{Destination: Destination{Host: host}}}
+	if len(service.Versions) > 0 {
+		// Split the traffic between the subsets of the versions.
+		weights := service.VersionWeights()
+		destinations = make([]HTTPRouteDestination, 0, len(service.Versions))
+		for i, version := range service.Versions {
+			destinations = append(destinations, HTTPRouteDestination{
+				Destination: Destination{Host: host, Subset: version.Name},
+				Weight:      weights[i],
+			})
+		}
+	}
+	route := func(name string, match []HTTPMatchRequest) HTTPRoute {
+		r := HTTPRoute{
+			Name:  name,
+			Match: match,
+			Route: destinations,
+		}
+		if mesh.RequestTimeout > 0 {
+			r.Timeout = formatDuration(mesh.RequestTimeout)
+		}
+		if mesh.RequestRetries > 0 {
+			r.Retries = &HTTPRetry{Attempts: mesh.RequestRetries}
+		}
+		return r
+	}
+
+	if service.Type == svctype.ServiceTCP {
+		// Connections carry no HTTP requests to time out or retry, so only the
+		// destinations are routed.
+		routes := make([]TCPRoute, 0, len(callers)+1)
+		for _, caller := range callers {
+			routes = append(routes, TCPRoute{
+				Match: []L4MatchAttributes{{SourceLabels: map[string]string{"name": caller}}},
+				Route: destinations,
+			})
+		}
+		routes = append(routes, TCPRoute{Route: destinations})
+		virtualService.Spec = VirtualServiceSpec{
+			Hosts: []string{host},
+			TCP:   routes,
+		}
+		return
+	}
+
+	routes := make([]HTTPRoute, 0, len(callers)+1)
+	for _, caller := range callers {
+		routes = append(routes, route(
+			fmt.Sprintf("%s-to-%s", caller, service.Name),
+			[]HTTPMatchRequest{{SourceLabels: map[string]string{"name": caller}}}))
+	}
+	routes = append(routes, route("default", nil))
+
+	virtualService.Spec = VirtualServiceSpec{
+		Hosts: []string{host},
+		HTTP:  routes,
+	}
+	return
+}
+
+// formatDuration formats d in seconds (e.g. "90s", "0.5s"), as protobuf
+// Durations do not accept minutes or hours.
+func formatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
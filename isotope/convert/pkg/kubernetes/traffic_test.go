@@ -0,0 +1,87 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+func TestMakeDestinationRule(t *testing.T) {
+	tests := []struct {
+		mode          string
+		trafficPolicy *TrafficPolicy
+	}{
+		{"", &TrafficPolicy{TLS: &ClientTLSSettings{Mode: "ISTIO_MUTUAL"}}},
+		{"STRICT", &TrafficPolicy{TLS: &ClientTLSSettings{Mode: "ISTIO_MUTUAL"}}},
+		{"PERMISSIVE", &TrafficPolicy{TLS: &ClientTLSSettings{Mode: "ISTIO_MUTUAL"}}},
+		{"DISABLE", nil},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.mode, func(t *testing.T) {
+			t.Parallel()
+
+			rule := makeDestinationRule(
+				svc.Service{Name: "a"}, MeshOptions{PeerAuthenticationMode: test.mode})
+			if !reflect.DeepEqual(test.trafficPolicy, rule.Spec.TrafficPolicy) {
+				t.Errorf("expected %v; actual %v", test.trafficPolicy, rule.Spec.TrafficPolicy)
+			}
+		})
+	}
+}
+
+func TestMakeVirtualService_Timeout(t *testing.T) {
+	tests := []struct {
+		requestTimeout time.Duration
+		timeout        string
+	}{
+		{0, ""},
+		{500 * time.Millisecond, "0.5s"},
+		{time.Second, "1s"},
+		{90 * time.Second, "90s"},
+		{2 * time.Hour, "7200s"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.requestTimeout.String(), func(t *testing.T) {
+			t.Parallel()
+
+			virtualService := makeVirtualService(
+				svc.Service{Name: "a"}, nil, MeshOptions{RequestTimeout: test.requestTimeout})
+			timeout := virtualService.Spec.HTTP[0].Timeout
+			if test.timeout != timeout {
+				t.Errorf("expected %v; actual %v", test.timeout, timeout)
+			}
+		})
+	}
+}
+
+func TestServiceGraphToKubernetesObjects_InvalidPeerAuthenticationMode(t *testing.T) {
+	serviceGraph := graph.ServiceGraph{Services: []svc.Service{{Name: "a"}}}
+	expected := ErrInvalidPeerAuthenticationMode{"strict"}
+	_, err := ServiceGraphToKubernetesObjects(
+		serviceGraph, nil, "", 0, 0, nil, "", "ISTIO",
+		MeshOptions{PeerAuthenticationMode: "strict"})
+	if err != expected {
+		t.Errorf("expected %v; actual %v", expected, err)
+	}
+}
@@ -5,7 +5,6 @@ go 1.14
 require (
 	github.com/docker/go-units v0.4.0
 	github.com/ghodss/yaml v1.0.0
-	github.com/google/uuid v1.1.1
 	github.com/hashicorp/go-multierror v1.1.0
 	github.com/prometheus/client_golang v1.5.1
 	github.com/spf13/cobra v0.0.7
@@ -17,6 +17,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"math/rand"
+	"net"
 	"net/http"
 	"os"
 	"path"
@@ -25,6 +27,7 @@ import (
 	"istio.io/pkg/log"
 
 	"istio.io/tools/isotope/convert/pkg/consts"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
 	"istio.io/tools/isotope/service/pkg/srv"
 	"istio.io/tools/isotope/service/pkg/srv/prometheus"
 )
@@ -42,6 +45,19 @@ var (
 	maxIdleConnectionsPerHostFlag = flag.Int(
 		"max-idle-connections-per-host", 0,
 		"maximum number of TCP connections to keep open per host")
+
+	callTreesFlag = flag.Bool(
+		"call-trees", false,
+		"record the call tree of every request, not only of those with the "+
+			srv.RecordCallTreeHeader+" header")
+
+	callTreeLogFractionFlag = flag.Float64(
+		"call-tree-log-fraction", 0,
+		"fraction between 0 and 1 of the call trees recorded by entrypoints which are logged")
+
+	seedFlag = flag.Int64(
+		"seed", 0,
+		"seed for random decisions such as call probabilities; 0 seeds from the current time")
 )
 
 func main() {
@@ -49,19 +65,33 @@ func main() {
 
 	setMaxProcs()
 	setMaxIdleConnectionsPerHost(*maxIdleConnectionsPerHostFlag)
+	if *seedFlag != 0 {
+		rand.Seed(*seedFlag)
+	}
 
 	serviceName, ok := os.LookupEnv(consts.ServiceNameEnvKey)
 	if !ok {
 		log.Fatalf(`env var "%s" is not set`, consts.ServiceNameEnvKey)
 	}
 
+	// Only the services with versions run as one of them.
+	serviceVersion := os.Getenv(consts.ServiceVersionEnvKey)
+
 	defaultHandler, err := srv.HandlerFromServiceGraphYAML(
-		serviceGraphYAMLFilePath, serviceName)
+		serviceGraphYAMLFilePath, serviceName, serviceVersion)
 	if err != nil {
 		log.Fatalf("%s", err)
 	}
+	defaultHandler.CallTrees = srv.CallTreeOptions{
+		Record:      *callTreesFlag,
+		LogFraction: *callTreeLogFractionFlag,
+	}
 
-	err = serveWithPrometheus(defaultHandler)
+	if defaultHandler.Service.Type == svctype.ServiceTCP {
+		err = serveTCPWithPrometheus(defaultHandler)
+	} else {
+		err = serveWithPrometheus(defaultHandler)
+	}
 	if err != nil {
 		log.Fatalf("%s", err)
 	}
@@ -82,6 +112,29 @@ func serveWithPrometheus(defaultHandler http.Handler) error {
 	return nil
 }
 
+// serveTCPWithPrometheus serves the TCP service on the service port, and its
+// Prometheus endpoint over HTTP on the metrics port.
+func serveTCPWithPrometheus(defaultHandler srv.Handler) error {
+	log.Infof(`exposing Prometheus endpoint "%s" on port %v`,
+		promEndpoint, consts.ServiceMetricsPort)
+	http.Handle(promEndpoint, prometheus.Handler())
+	metricsAddr := fmt.Sprintf(":%d", consts.ServiceMetricsPort)
+	errs := make(chan error, 2)
+	go func() {
+		errs <- http.ListenAndServe(metricsAddr, nil)
+	}()
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", consts.ServicePort))
+	if err != nil {
+		return err
+	}
+	log.Infof("listening for TCP requests on port %v\n", consts.ServicePort)
+	go func() {
+		errs <- defaultHandler.ServeTCP(listener)
+	}()
+	return <-errs
+}
+
 func setMaxProcs() {
 	numCPU := runtime.NumCPU()
 	maxProcs := runtime.GOMAXPROCS(0)
@@ -93,4 +146,5 @@ func setMaxProcs() {
 
 func setMaxIdleConnectionsPerHost(n int) {
 	http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost = n
+	srv.SetMaxIdleTCPConnectionsPerHost(n)
 }
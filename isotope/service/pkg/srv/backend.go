@@ -0,0 +1,172 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svckind"
+	"istio.io/tools/isotope/service/pkg/srv/prometheus"
+)
+
+// cache emulates the hits of a service of kind cache.
+type cache struct {
+	settings svc.Cache
+}
+
+// newCache returns the cache of the service, or nil if it is not a cache.
+func newCache(service svc.Service) *cache {
+	if service.Kind != svckind.KindCache {
+		return nil
+	}
+	c := &cache{}
+	if service.Cache != nil {
+		c.settings = *service.Cache
+	}
+	return c
+}
+
+// lookup returns true on a hit, after the hit latency.
+func (c *cache) lookup() bool {
+	hit := rand.Float64() < float64(c.settings.HitRatio)
+	prometheus.RecordCacheLookup(hit)
+	if hit {
+		time.Sleep(time.Duration(c.settings.HitLatency))
+	}
+	return hit
+}
+
+// database emulates the queries of a service of kind database.
+type database struct {
+	settings svc.Database
+	// connections holds a token per connection in use, if the pool is
+	// limited.
+	connections chan struct{}
+}
+
+// newDatabase returns the database of the service, or nil if it is not a
+// database.
+func newDatabase(service svc.Service) *database {
+	if service.Kind != svckind.KindDatabase {
+		return nil
+	}
+	db := &database{}
+	if service.Database != nil {
+		db.settings = *service.Database
+	}
+	if db.settings.MaxConnections > 0 {
+		db.connections = make(chan struct{}, db.settings.MaxConnections)
+	}
+	return db
+}
+
+// query runs a query on a connection of the pool. It returns false if no
+// connection frees up within the connection timeout or before ctx is done.
+func (db *database) query(ctx context.Context) bool {
+	if db.connections != nil {
+		if db.settings.ConnectionTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(
+				ctx, time.Duration(db.settings.ConnectionTimeout))
+			defer cancel()
+		}
+		start := time.Now()
+		select {
+		case db.connections <- struct{}{}:
+			prometheus.RecordDatabaseConnectionWait(time.Since(start))
+		case <-ctx.Done():
+			prometheus.RecordDatabaseConnectionWait(time.Since(start))
+			return false
+		}
+		defer func() { <-db.connections }()
+	}
+
+	time.Sleep(db.settings.QueryLatency.Quantile(rand.Float64()))
+	return true
+}
+
+// defaultQueueCapacity is the capacity of queues which do not set one, as
+// the requests pending in a queue are buffered in memory.
+const defaultQueueCapacity = 10000
+
+// workQueue emulates the asynchronous processing of a service of kind queue.
+// A fixed pool of workers processes the accepted requests in order.
+type workQueue struct {
+	// mu guards pending, so that the depth of the queue is reported in order.
+	mu sync.Mutex
+	// pending is the number of requests accepted but not processed yet.
+	pending int
+	// work holds the requests waiting for a worker. Its capacity is that of
+	// the queue.
+	work chan func()
+}
+
+// newWorkQueue returns the queue of the service, or nil if it is not a queue.
+// It starts the workers of the queue.
+func newWorkQueue(service svc.Service) *workQueue {
+	if service.Kind != svckind.KindQueue {
+		return nil
+	}
+	var settings svc.Queue
+	if service.Queue != nil {
+		settings = *service.Queue
+	}
+	capacity := int(settings.Capacity)
+	if capacity <= 0 {
+		capacity = defaultQueueCapacity
+	}
+	workers := int(settings.Workers)
+	if workers <= 0 {
+		workers = 1
+	}
+
+	q := &workQueue{work: make(chan func(), capacity)}
+	for i := 0; i < workers; i++ {
+		go q.process()
+	}
+	return q
+}
+
+// enqueue accepts work to process later, returning false if the queue is
+// full.
+func (q *workQueue) enqueue(work func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// The pending requests include those being processed, so the buffer of
+	// work always has room for an accepted request.
+	if q.pending >= cap(q.work) {
+		return false
+	}
+	q.pending++
+	prometheus.SetWorkQueueDepth(q.pending)
+	q.work <- work
+	return true
+}
+
+// process runs the work accepted by the queue, one request at a time.
+func (q *workQueue) process() {
+	for work := range q.work {
+		work()
+
+		q.mu.Lock()
+		q.pending--
+		prometheus.SetWorkQueueDepth(q.pending)
+		q.mu.Unlock()
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/graph/duration"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svckind"
+)
+
+// unreachableScript fails whenever the script of a service runs, as the
+// service it calls is not in the graph.
+var unreachableScript = script.Script{script.RequestCommand{ServiceName: "missing"}}
+
+func TestHandler_Kinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		service svc.Service
+		// setup takes resources of the handler before the request.
+		setup  func(h Handler)
+		status int
+	}{
+		{
+			name: "cache hit",
+			service: svc.Service{
+				Kind:   svckind.KindCache,
+				Cache:  &svc.Cache{HitRatio: 1},
+				Script: unreachableScript,
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cache miss",
+			service: svc.Service{
+				Kind:   svckind.KindCache,
+				Cache:  &svc.Cache{HitRatio: 0},
+				Script: unreachableScript,
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name: "database",
+			service: svc.Service{
+				Kind:     svckind.KindDatabase,
+				Database: &svc.Database{MaxConnections: 1},
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "database pool exhausted",
+			service: svc.Service{
+				Kind: svckind.KindDatabase,
+				Database: &svc.Database{
+					MaxConnections:    1,
+					ConnectionTimeout: duration.Duration(10 * time.Millisecond),
+				},
+			},
+			setup:  func(h Handler) { h.database.connections <- struct{}{} },
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			// The script runs after responding, so its failure is not seen.
+			name: "queue",
+			service: svc.Service{
+				Kind:   svckind.KindQueue,
+				Script: unreachableScript,
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.service.Name = "a"
+			h, err := newHandler(test.service, "", nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.setup != nil {
+				test.setup(h)
+			}
+			status, _ := h.handle(context.Background(), time.Now(), http.Header{}, nil)
+			if test.status != status {
+				t.Errorf("expected %v; actual %v", test.status, status)
+			}
+		})
+	}
+}
+
+func TestWorkQueue_Enqueue(t *testing.T) {
+	q := newWorkQueue(svc.Service{
+		Kind:  svckind.KindQueue,
+		Queue: &svc.Queue{Capacity: 1, Workers: 1},
+	})
+
+	unblock := make(chan struct{})
+	done := make(chan struct{})
+	if !q.enqueue(func() { <-unblock; close(done) }) {
+		t.Fatal("expected the first request to be accepted")
+	}
+	if q.enqueue(func() {}) {
+		t.Error("expected the request beyond the capacity to be rejected")
+	}
+
+	close(unblock)
+	<-done
+	// The request is accepted once the pending one has been processed.
+	deadline := time.Now().Add(time.Second)
+	for !q.enqueue(func() {}) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the request to be accepted after processing")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWorkQueue_Workers(t *testing.T) {
+	q := newWorkQueue(svc.Service{
+		Kind:  svckind.KindQueue,
+		Queue: &svc.Queue{Capacity: 3, Workers: 2},
+	})
+
+	// Both workers pick up a request, while the third one waits.
+	started := make(chan struct{}, 3)
+	unblock := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		if !q.enqueue(func() { started <- struct{}{}; <-unblock }) {
+			t.Fatalf("expected request %d to be accepted", i)
+		}
+	}
+	<-started
+	<-started
+	select {
+	case <-started:
+		t.Error("expected the third request to wait for a worker")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if q.enqueue(func() {}) {
+		t.Error("expected the request beyond the capacity to be rejected")
+	}
+
+	close(unblock)
+	<-started
+}
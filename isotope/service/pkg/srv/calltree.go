@@ -0,0 +1,232 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"istio.io/pkg/log"
+)
+
+const (
+	// RecordCallTreeHeader is the request header opting a request into
+	// recording its call tree. It is forwarded to the called services.
+	RecordCallTreeHeader = "X-Isotope-Record-Call-Tree"
+	// CallTreeHeader is the response header holding the call tree of a request
+	// as JSON.
+	CallTreeHeader = "X-Isotope-Call-Tree"
+
+	// maxCallTreeSize bounds the size of the CallTreeHeader, well below the
+	// 60 KiB Envoy allows for all the headers of a response.
+	maxCallTreeSize = 16 << 10
+)
+
+// CallTreeOptions controls the recording of call trees, which is otherwise
+// only done for requests with the RecordCallTreeHeader.
+type CallTreeOptions struct {
+	// Record records the call tree of every request.
+	Record bool
+
+	// LogFraction is the fraction between 0 and 1 of the call trees recorded
+	// by an entrypoint which are logged.
+	LogFraction float64
+}
+
+// CallTree describes how a service handled a request: the steps of its script
+// and the calls it made, including the call trees of the called services.
+type CallTree struct {
+	Service  string      `json:"service"`
+	Version  string      `json:"version,omitempty"`
+	Status   int         `json:"status"`
+	Duration string      `json:"duration"`
+	Error    string      `json:"error,omitempty"`
+	Steps    []*CallStep `json:"steps,omitempty"`
+	// Truncated is set when some of the tree was left out to fit in the
+	// CallTreeHeader.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CallStep is a step of the script of a service.
+type CallStep struct {
+	Duration string      `json:"duration"`
+	Sleeps   []string    `json:"sleeps,omitempty"`
+	Calls    []*CallNode `json:"calls,omitempty"`
+
+	// mu guards the step against the commands of a concurrent command.
+	mu sync.Mutex
+}
+
+// CallNode is a call to another service.
+type CallNode struct {
+	Service  string    `json:"service"`
+	Skipped  bool      `json:"skipped,omitempty"`
+	Status   int       `json:"status,omitempty"`
+	Duration string    `json:"duration,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Tree     *CallTree `json:"tree,omitempty"`
+}
+
+// newCallTree returns an empty call tree if the request of header records its
+// call tree, or nil. The header is updated so that the called services record
+// theirs as well.
+func (h Handler) newCallTree(forwardableHeader http.Header) *CallTree {
+	if forwardableHeader.Get(RecordCallTreeHeader) == "" {
+		if !h.CallTrees.Record {
+			return nil
+		}
+		forwardableHeader.Set(RecordCallTreeHeader, "true")
+	}
+	return &CallTree{Service: h.Service.Name, Version: h.version}
+}
+
+// finishCallTree completes the tree with the response and, if the service is
+// an entrypoint, logs it at the configured fraction. It returns the tree as
+// JSON, truncated to fit in maxCallTreeSize.
+func (h Handler) finishCallTree(tree *CallTree, status int, duration time.Duration) string {
+	tree.Status = status
+	tree.Duration = formatDuration(duration)
+	b, err := json.Marshal(tree)
+	if err != nil {
+		log.Errorf("%s", err)
+		return ""
+	}
+	if h.Service.IsEntrypoint && rand.Float64() < h.CallTrees.LogFraction {
+		log.Infof("call tree: %s", b)
+	}
+	if len(b) > maxCallTreeSize {
+		b, err = truncateCallTree(tree, maxCallTreeSize)
+		if err != nil {
+			log.Errorf("%s", err)
+			return ""
+		}
+	}
+	return string(b)
+}
+
+// truncateCallTree returns the tree as JSON of at most size bytes. The call
+// trees of the called services are left out from the deepest up, and then the
+// steps of the tree itself.
+func truncateCallTree(tree *CallTree, size int) ([]byte, error) {
+	for depth := callTreeDepth(tree) - 1; depth >= 0; depth-- {
+		b, err := json.Marshal(pruneCallTree(tree, depth))
+		if err != nil || len(b) <= size {
+			return b, err
+		}
+	}
+	return json.Marshal(&CallTree{
+		Service:   tree.Service,
+		Version:   tree.Version,
+		Status:    tree.Status,
+		Duration:  tree.Duration,
+		Error:     tree.Error,
+		Truncated: true,
+	})
+}
+
+// callTreeDepth returns the number of nested call trees on the longest path
+// of the tree, counting the tree itself.
+func callTreeDepth(tree *CallTree) int {
+	depth := 0
+	for _, step := range tree.Steps {
+		for _, call := range step.Calls {
+			if call.Tree != nil {
+				if d := callTreeDepth(call.Tree); d > depth {
+					depth = d
+				}
+			}
+		}
+	}
+	return depth + 1
+}
+
+// pruneCallTree returns a copy of the tree keeping the call trees of the called
+// services down to depth levels below it. The trees left out are marked as
+// truncated in all the trees above them.
+func pruneCallTree(tree *CallTree, depth int) *CallTree {
+	pruned := *tree
+	pruned.Steps = make([]*CallStep, 0, len(tree.Steps))
+	for _, step := range tree.Steps {
+		prunedStep := &CallStep{
+			Duration: step.Duration,
+			Sleeps:   step.Sleeps,
+			Calls:    make([]*CallNode, 0, len(step.Calls)),
+		}
+		for _, call := range step.Calls {
+			prunedCall := *call
+			if call.Tree != nil {
+				if depth == 0 {
+					prunedCall.Tree = nil
+					pruned.Truncated = true
+				} else {
+					prunedCall.Tree = pruneCallTree(call.Tree, depth-1)
+					pruned.Truncated = pruned.Truncated || prunedCall.Tree.Truncated
+				}
+			}
+			prunedStep.Calls = append(prunedStep.Calls, &prunedCall)
+		}
+		pruned.Steps = append(pruned.Steps, prunedStep)
+	}
+	return &pruned
+}
+
+// addStep appends a step to the tree, which may be nil when not recording.
+func (t *CallTree) addStep() *CallStep {
+	if t == nil {
+		return nil
+	}
+	step := &CallStep{}
+	t.Steps = append(t.Steps, step)
+	return step
+}
+
+func (s *CallStep) addSleep(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.Sleeps = append(s.Sleeps, formatDuration(d))
+	s.mu.Unlock()
+}
+
+func (s *CallStep) addCall(call *CallNode) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.Calls = append(s.Calls, call)
+	s.mu.Unlock()
+}
+
+// parseCallTree returns the call tree in the header of a response, if any.
+func parseCallTree(header http.Header) *CallTree {
+	value := header.Get(CallTreeHeader)
+	if value == "" {
+		return nil
+	}
+	var tree CallTree
+	if err := json.Unmarshal([]byte(value), &tree); err != nil {
+		log.Errorf("invalid call tree: %s", err)
+		return nil
+	}
+	return &tree
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// chainCallTree returns a tree of services calling each other in a chain of
+// depth services, each sleeping sleeps times.
+func chainCallTree(depth int, sleeps int) *CallTree {
+	var tree *CallTree
+	for i := depth - 1; i >= 0; i-- {
+		step := &CallStep{Duration: "1ms"}
+		for j := 0; j < sleeps; j++ {
+			step.Sleeps = append(step.Sleeps, "1ms")
+		}
+		if tree != nil {
+			step.Calls = []*CallNode{{Service: tree.Service, Status: 200, Tree: tree}}
+		}
+		tree = &CallTree{
+			Service: string(rune('a' + i)), Status: 200, Steps: []*CallStep{step},
+		}
+	}
+	return tree
+}
+
+func TestTruncateCallTree(t *testing.T) {
+	tests := []struct {
+		name  string
+		tree  *CallTree
+		size  int
+		depth int
+		steps bool
+	}{
+		{"fits", chainCallTree(3, 1), 1 << 10, 3, true},
+		{"nested trees", chainCallTree(3, 50), 1 << 10, 2, true},
+		{"own steps", chainCallTree(1, 1000), 1 << 10, 1, false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := truncateCallTree(test.tree, test.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(b) > test.size {
+				t.Errorf("expected at most %d bytes; actual %d", test.size, len(b))
+			}
+			var tree CallTree
+			if err := json.Unmarshal(b, &tree); err != nil {
+				t.Fatal(err)
+			}
+			if depth := callTreeDepth(&tree); test.depth != depth {
+				t.Errorf("expected depth %v; actual %v", test.depth, depth)
+			}
+			if steps := len(tree.Steps) > 0; test.steps != steps {
+				t.Errorf("expected steps %v; actual %v", test.steps, steps)
+			}
+			if truncated := callTreeDepth(test.tree) != test.depth || !test.steps; truncated != tree.Truncated {
+				t.Errorf("expected truncated %v; actual %v", truncated, tree.Truncated)
+			}
+		})
+	}
+}
+
+func TestFinishCallTree_Truncated(t *testing.T) {
+	var h Handler
+	header := h.finishCallTree(chainCallTree(1, maxCallTreeSize), 200, 0)
+	if len(header) > maxCallTreeSize {
+		t.Errorf("expected at most %d bytes; actual %d", maxCallTreeSize, len(header))
+	}
+	if !strings.Contains(header, `"truncated":true`) {
+		t.Errorf("expected a truncated tree; actual %v", header)
+	}
+}
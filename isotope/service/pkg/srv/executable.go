@@ -35,21 +35,24 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
+// execute runs a step of a script, recording it in callStep unless it is nil.
 func execute(
 	step interface{},
 	forwardableHeader http.Header,
-	serviceTypes map[string]svctype.ServiceType) error {
+	peers map[string]peer,
+	callStep *CallStep) error {
 	switch cmd := step.(type) {
 	case script.SleepCommand:
 		executeSleepCommand(cmd)
+		callStep.addSleep(time.Duration(cmd))
 	case script.RequestCommand:
 		if err := executeRequestCommand(
-			cmd, forwardableHeader, serviceTypes); err != nil {
+			cmd, forwardableHeader, peers, callStep); err != nil {
 			return err
 		}
 	case script.ConcurrentCommand:
 		if err := executeConcurrentCommand(
-			cmd, forwardableHeader, serviceTypes); err != nil {
+			cmd, forwardableHeader, peers, callStep); err != nil {
 			return err
 		}
 	default:
@@ -70,23 +73,43 @@ func shouldSkipRequest(cmd script.RequestCommand) bool {
 	return rand.Intn(100) < (100 - cmd.Probability)
 }
 
-// Execute sends an HTTP request to another service. Assumes DNS is available
-// which maps exe.ServiceName to the relevant URL to reach the service.
+// Execute sends a request to another service, over HTTP or its TCP protocol.
+// Assumes DNS is available which maps the host of exe.ServiceName to the
+// relevant address to reach the service.
 func executeRequestCommand(
 	cmd script.RequestCommand,
 	forwardableHeader http.Header,
-	serviceTypes map[string]svctype.ServiceType) error {
+	peers map[string]peer,
+	callStep *CallStep) (err error) {
 
 	if shouldSkipRequest(cmd) {
+		callStep.addCall(&CallNode{Service: cmd.ServiceName, Skipped: true})
 		return nil
 	}
 
 	destName := cmd.ServiceName
-	_, ok := serviceTypes[destName]
+	dest, ok := peers[destName]
 	if !ok {
 		return fmt.Errorf("service %s does not exist", destName)
 	}
-	response, err := sendRequest(destName, cmd.Size, forwardableHeader)
+
+	call := &CallNode{Service: destName}
+	startTime := time.Now()
+	if callStep != nil {
+		defer func() {
+			call.Duration = formatDuration(time.Since(startTime))
+			if err != nil {
+				call.Error = err.Error()
+			}
+			callStep.addCall(call)
+		}()
+	}
+
+	if dest.serviceType == svctype.ServiceTCP {
+		return executeTCPRequestCommand(cmd, dest.host, call)
+	}
+	response, err := sendRequest(
+		dest.host, cmd.Size, forwardableHeader, cmd.NewConnection)
 	if err != nil {
 		return err
 	}
@@ -96,6 +119,11 @@ func executeRequestCommand(
 	defer readAllAndClose(response.Body)
 	defer prometheus.RecordRequestSent(destName, uint64(cmd.Size))
 
+	call.Status = response.StatusCode
+	if callStep != nil {
+		call.Tree = parseCallTree(response.Header)
+	}
+
 	log.Debugf("%s responded with %s", destName, response.Status)
 	if response.StatusCode != http.StatusOK {
 		return fmt.Errorf(
@@ -105,6 +133,27 @@ func executeRequestCommand(
 	return nil
 }
 
+// executeTCPRequestCommand exchanges a request and a response with the TCP
+// service at destHost. Neither trace headers nor call trees are carried over
+// TCP.
+func executeTCPRequestCommand(
+	cmd script.RequestCommand, destHost string, call *CallNode) error {
+	status, err := sendTCPRequest(destHost, cmd.Size, cmd.NewConnection)
+	if err != nil {
+		return err
+	}
+	prometheus.RecordRequestSent(cmd.ServiceName, uint64(cmd.Size))
+
+	call.Status = status
+	log.Debugf("%s responded with %d", cmd.ServiceName, status)
+	if status != http.StatusOK {
+		return fmt.Errorf(
+			"service %s responded with %d %s",
+			cmd.ServiceName, status, http.StatusText(status))
+	}
+	return nil
+}
+
 func readAllAndClose(r io.ReadCloser) error {
 	if _, err := io.Copy(ioutil.Discard, r); err != nil {
 		return err
@@ -117,7 +166,8 @@ func readAllAndClose(r io.ReadCloser) error {
 func executeConcurrentCommand(
 	cmd script.ConcurrentCommand,
 	forwardableHeader http.Header,
-	serviceTypes map[string]svctype.ServiceType) (errs error) {
+	peers map[string]peer,
+	callStep *CallStep) (errs error) {
 	numSubCmds := len(cmd)
 	wg := sync.WaitGroup{}
 	wg.Add(numSubCmds)
@@ -125,7 +175,7 @@ func executeConcurrentCommand(
 		go func(step interface{}) {
 			defer wg.Done()
 
-			err := execute(step, forwardableHeader, serviceTypes)
+			err := execute(step, forwardableHeader, peers, callStep)
 			if err != nil {
 				errs = multierror.Append(errs, err)
 			}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"time"
 
 	"github.com/ghodss/yaml"
 
@@ -30,9 +31,10 @@ import (
 )
 
 // HandlerFromServiceGraphYAML makes a handler to emulate the service with name
-// serviceName in the service graph represented by the YAML file at path.
+// serviceName in the service graph represented by the YAML file at path. If
+// serviceVersion is set, the handler emulates that version of the service.
 func HandlerFromServiceGraphYAML(
-	path string, serviceName string) (Handler, error) {
+	path string, serviceName string, serviceVersion string) (Handler, error) {
 
 	serviceGraph, err := serviceGraphFromYAMLFile(path)
 	if err != nil {
@@ -43,11 +45,26 @@ func HandlerFromServiceGraphYAML(
 	if err != nil {
 		return Handler{}, err
 	}
+	if serviceVersion != "" {
+		service, err = service.WithVersion(serviceVersion)
+		if err != nil {
+			return Handler{}, err
+		}
+	}
 	_ = logService(service)
 
-	serviceTypes := extractServiceTypes(serviceGraph)
+	return newHandler(
+		service, serviceVersion, extractServiceTypes(serviceGraph),
+		extractPeers(serviceGraph, service.EffectiveNamespace()))
+}
 
-	responsePayload, err := makeRandomByteArray(service.ResponseSize)
+// newHandler makes a handler to emulate the version, if set, of service,
+// calling the services of the graph through peers.
+func newHandler(
+	service svc.Service, version string,
+	serviceTypes map[string]svctype.ServiceType, peers map[string]peer) (
+	Handler, error) {
+	responsePayload, err := makeRandomByteArray(service.MaxResponseSize())
 	if err != nil {
 		return Handler{}, err
 	}
@@ -55,7 +72,14 @@ func HandlerFromServiceGraphYAML(
 	return Handler{
 		Service:         service,
 		ServiceTypes:    serviceTypes,
+		version:         version,
+		peers:           peers,
 		responsePayload: responsePayload,
+		limiter:         newLimiter(service.MaxConcurrency, service.QueueSize),
+		cache:           newCache(service),
+		database:        newDatabase(service),
+		workQueue:       newWorkQueue(service),
+		startTime:       time.Now(),
 	}, nil
 }
 
@@ -118,3 +142,26 @@ func extractServiceTypes(
 	}
 	return types
 }
+
+// peer is how a service reaches another service of the graph.
+type peer struct {
+	// host is the name of the service within the namespace of the caller, its
+	// fully qualified domain name otherwise.
+	host string
+	// serviceType is the protocol the service speaks.
+	serviceType svctype.ServiceType
+}
+
+// extractPeers builds a map from service name to how callers in namespace
+// reach it.
+func extractPeers(
+	serviceGraph graph.ServiceGraph, namespace string) map[string]peer {
+	peers := make(map[string]peer, len(serviceGraph.Services))
+	for _, service := range serviceGraph.Services {
+		peers[service.Name] = peer{
+			host:        service.Host(namespace),
+			serviceType: service.Type,
+		}
+	}
+	return peers
+}
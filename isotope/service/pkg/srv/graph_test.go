@@ -0,0 +1,94 @@
+// Copyright 2018 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/tools/isotope/convert/pkg/graph"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+)
+
+// TestHandlerFromServiceGraphYAML_RoundTrip checks the settings of versions
+// survive the converter marshalling the graph into the ConfigMap, including
+// zero values overriding the service's.
+func TestHandlerFromServiceGraphYAML_RoundTrip(t *testing.T) {
+	var serviceGraph graph.ServiceGraph
+	err := yaml.Unmarshal([]byte(`
+services:
+- name: a
+  errorRate: 50%
+  responseSize: 1KiB
+  script:
+  - sleep: 10ms
+  versions:
+  - name: v1
+  - name: v2
+    errorRate: 0%
+    responseSize: 0
+    script: []
+`), &serviceGraph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphYAML, err := yaml.Marshal(serviceGraph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "isotope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "service-graph.yaml")
+	if err := ioutil.WriteFile(path, graphYAML, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version      string
+		errorRate    float64
+		responseSize uint64
+		script       script.Script
+	}{
+		{"v1", 0.5, 1024, serviceGraph.Services[0].Script},
+		{"v2", 0, 0, script.Script{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			handler, err := HandlerFromServiceGraphYAML(path, "a", test.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			service := handler.Service
+			if float64(service.ErrorRate) != test.errorRate {
+				t.Errorf("expected %v; actual %v", test.errorRate, service.ErrorRate)
+			}
+			if uint64(service.ResponseSize) != test.responseSize {
+				t.Errorf("expected %v; actual %v", test.responseSize, service.ResponseSize)
+			}
+			if !reflect.DeepEqual(test.script, service.Script) {
+				t.Errorf("expected %v; actual %v", test.script, service.Script)
+			}
+		})
+	}
+}
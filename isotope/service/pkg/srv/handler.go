@@ -15,21 +15,37 @@
 package srv
 
 import (
+	"context"
+	"math/rand"
 	"net/http"
 	"time"
 
 	"istio.io/pkg/log"
 
 	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svckind"
 	"istio.io/tools/isotope/convert/pkg/graph/svctype"
 	"istio.io/tools/isotope/service/pkg/srv/prometheus"
 )
 
 // Handler handles the default endpoint by emulating its Service.
 type Handler struct {
-	Service         svc.Service
-	ServiceTypes    map[string]svctype.ServiceType
+	Service      svc.Service
+	ServiceTypes map[string]svctype.ServiceType
+	CallTrees    CallTreeOptions
+	// version is the version of the service emulated, if any.
+	version string
+	// peers maps the name of each service of the graph to how the service
+	// reaches it.
+	peers           map[string]peer
 	responsePayload []byte
+	limiter         *limiter
+	cache           *cache
+	database        *database
+	workQueue       *workQueue
+	// startTime is when the handler was made, from which the schedule of the
+	// service is evaluated.
+	startTime time.Time
 }
 
 func (h Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
@@ -37,26 +53,107 @@ func (h Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 
 	prometheus.RecordRequestReceived()
 
-	respond := func(status int) {
-		writer.WriteHeader(status)
-		if _, err := writer.Write(h.responsePayload); err != nil {
-			log.Errorf("%s", err)
+	forwardableHeader := extractForwardableHeader(request.Header)
+	tree := h.newCallTree(forwardableHeader)
+	status, responsePayload := h.handle(
+		request.Context(), startTime, forwardableHeader, tree)
+
+	if tree != nil {
+		writer.Header().Set(
+			CallTreeHeader, h.finishCallTree(tree, status, time.Since(startTime)))
+	}
+	writer.WriteHeader(status)
+	if _, err := writer.Write(responsePayload); err != nil {
+		log.Errorf("%s", err)
+	}
+
+	stopTime := time.Now()
+	duration := stopTime.Sub(startTime)
+	prometheus.RecordResponseSent(duration, len(responsePayload), status)
+}
+
+// handle emulates the service for a request received at startTime, returning
+// the status and the payload of the response. The steps of the script are
+// recorded in tree, unless it is nil.
+func (h Handler) handle(
+	ctx context.Context, startTime time.Time, forwardableHeader http.Header,
+	tree *CallTree) (int, []byte) {
+	queueDuration, err := h.limiter.acquire(ctx)
+	if h.limiter != nil {
+		prometheus.RecordQueueWait(queueDuration)
+	}
+	if err != nil {
+		// Rejected requests are answered right away, without a payload, as an
+		// overloaded server shedding load would. Requests whose caller gave up
+		// while queued were not rejected.
+		if err == errQueueFull {
+			prometheus.RecordRequestRejected()
+		}
+		return http.StatusServiceUnavailable, nil
+	}
+	defer h.limiter.release()
+
+	behaviour := h.Service.BehaviourAt(startTime.Sub(h.startTime))
+	// The payload is made for the largest response size of the schedule.
+	responsePayload := h.responsePayload[:behaviour.ResponseSize]
+
+	if behaviour.ExtraSleep > 0 {
+		time.Sleep(behaviour.ExtraSleep)
+	}
+
+	switch h.Service.Kind {
+	case svckind.KindCache:
+		if h.cache.lookup() {
+			return statusOf(behaviour), responsePayload
+		}
+	case svckind.KindDatabase:
+		if !h.database.query(ctx) {
+			return http.StatusServiceUnavailable, responsePayload
 		}
+	case svckind.KindQueue:
+		// The script runs after responding, so it is not part of the tree.
+		accepted := h.workQueue.enqueue(func() {
+			if err := h.runScript(forwardableHeader, nil); err != nil {
+				log.Errorf("%s", err)
+			}
+		})
+		if !accepted {
+			return http.StatusServiceUnavailable, responsePayload
+		}
+		return statusOf(behaviour), responsePayload
+	}
 
-		stopTime := time.Now()
-		duration := stopTime.Sub(startTime)
-		prometheus.RecordResponseSent(duration, len(h.responsePayload), status)
+	if err := h.runScript(forwardableHeader, tree); err != nil {
+		log.Errorf("%s", err)
+		if tree != nil {
+			tree.Error = err.Error()
+		}
+		return http.StatusInternalServerError, responsePayload
 	}
 
+	return statusOf(behaviour), responsePayload
+}
+
+func (h Handler) runScript(forwardableHeader http.Header, tree *CallTree) error {
 	for _, step := range h.Service.Script {
-		forwardableHeader := extractForwardableHeader(request.Header)
-		err := execute(step, forwardableHeader, h.ServiceTypes)
+		stepStartTime := time.Now()
+		callStep := tree.addStep()
+		err := execute(step, forwardableHeader, h.peers, callStep)
+		if callStep != nil {
+			callStep.Duration = formatDuration(time.Since(stepStartTime))
+		}
 		if err != nil {
-			log.Errorf("%s", err)
-			respond(http.StatusInternalServerError)
-			return
+			return err
 		}
 	}
+	return nil
+}
 
-	respond(http.StatusOK)
+// statusOf returns the status of a successful request, failing it at the error
+// rate of the behaviour.
+func statusOf(behaviour svc.Behaviour) int {
+	if rand.Float64() < float64(behaviour.ErrorRate) {
+		return http.StatusInternalServerError
+	}
+	return http.StatusOK
 }
@@ -0,0 +1,191 @@
+// Copyright 2018 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/tools/isotope/convert/pkg/consts"
+	"istio.io/tools/isotope/convert/pkg/graph/duration"
+	"istio.io/tools/isotope/convert/pkg/graph/pct"
+	"istio.io/tools/isotope/convert/pkg/graph/script"
+	"istio.io/tools/isotope/convert/pkg/graph/size"
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+	"istio.io/tools/isotope/convert/pkg/graph/svctype"
+)
+
+func TestHandler_Schedule(t *testing.T) {
+	errorRate := pct.Percentage(1)
+	responseSize := size.ByteSize(20)
+	service := svc.Service{
+		Name:         "a",
+		ResponseSize: 10,
+		Schedule: []svc.Phase{
+			{
+				From:         duration.Duration(time.Minute),
+				To:           duration.Duration(2 * time.Minute),
+				ErrorRate:    &errorRate,
+				ResponseSize: &responseSize,
+			},
+			{From: duration.Duration(3 * time.Minute)},
+		},
+	}
+	h, err := newHandler(service, "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		elapsed      time.Duration
+		status       int
+		responseSize int
+	}{
+		{0, http.StatusOK, 10},
+		{90 * time.Second, http.StatusInternalServerError, 20},
+		{2 * time.Minute, http.StatusOK, 10},
+		{time.Hour, http.StatusOK, 10},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.elapsed.String(), func(t *testing.T) {
+			t.Parallel()
+
+			status, payload := h.handle(
+				context.Background(), h.startTime.Add(test.elapsed), http.Header{}, nil)
+			if test.status != status {
+				t.Errorf("expected %v; actual %v", test.status, status)
+			}
+			if test.responseSize != len(payload) {
+				t.Errorf("expected %v; actual %v", test.responseSize, len(payload))
+			}
+		})
+	}
+}
+
+func TestHandler_QueueFull(t *testing.T) {
+	h, err := newHandler(svc.Service{Name: "a", MaxConcurrency: 1}, "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.limiter.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	status, _ := h.handle(context.Background(), time.Now(), http.Header{}, nil)
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("expected %v; actual %v", http.StatusServiceUnavailable, status)
+	}
+}
+
+// serveServices serves each service of the graph with httptest, routing the
+// requests of http.DefaultClient to the services to their server until the
+// returned function is called.
+func serveServices(t *testing.T, services []svc.Service) func() {
+	t.Helper()
+	peers := make(map[string]peer, len(services))
+	for _, service := range services {
+		peers[service.Name] = peer{host: service.Name, serviceType: svctype.ServiceHTTP}
+	}
+	addrs := make(map[string]string, len(services))
+	servers := make([]*httptest.Server, 0, len(services))
+	for _, service := range services {
+		h, err := newHandler(service, "", nil, peers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := httptest.NewServer(h)
+		servers = append(servers, server)
+		addrs[fmt.Sprintf("%s:%d", service.Name, consts.ServicePort)] =
+			server.Listener.Addr().String()
+	}
+
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addrs[addr])
+		},
+	}
+	return func() {
+		http.DefaultClient.Transport = transport
+		for _, server := range servers {
+			server.Close()
+		}
+	}
+}
+
+func TestHandler_CallTree(t *testing.T) {
+	stop := serveServices(t, []svc.Service{
+		{Name: "a", IsEntrypoint: true, Script: script.Script{
+			script.ConcurrentCommand{
+				script.RequestCommand{ServiceName: "b"},
+				script.SleepCommand(time.Millisecond),
+			},
+			script.RequestCommand{ServiceName: "c"},
+		}},
+		{Name: "b", Script: script.Script{script.RequestCommand{ServiceName: "c"}}},
+		{Name: "c"},
+	})
+	defer stop()
+
+	request, err := http.NewRequest(
+		"GET", fmt.Sprintf("http://a:%d", consts.ServicePort), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set(RecordCallTreeHeader, "true")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readAllAndClose(response.Body)
+
+	var tree CallTree
+	if err := json.Unmarshal([]byte(response.Header.Get(CallTreeHeader)), &tree); err != nil {
+		t.Fatal(err)
+	}
+	expected := `a 200 [[b 200 [[c 200 []]]] [c 200 []]]`
+	if actual := summarizeCallTree(&tree); expected != actual {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+	if sleeps := tree.Steps[0].Sleeps; len(sleeps) != 1 {
+		t.Errorf("expected a sleep; actual %v", sleeps)
+	}
+}
+
+// summarizeCallTree describes the services and statuses of the tree, leaving
+// out its durations.
+func summarizeCallTree(tree *CallTree) string {
+	if tree == nil {
+		return "<nil>"
+	}
+	steps := make([]string, 0, len(tree.Steps))
+	for _, step := range tree.Steps {
+		calls := make([]string, 0, len(step.Calls))
+		for _, call := range step.Calls {
+			calls = append(calls, summarizeCallTree(call.Tree))
+		}
+		steps = append(steps, "["+strings.Join(calls, " ")+"]")
+	}
+	return fmt.Sprintf("%s %d [%s]", tree.Service, tree.Status, strings.Join(steps, " "))
+}
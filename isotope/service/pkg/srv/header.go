@@ -27,6 +27,7 @@ var (
 		"X-B3-Sampled",
 		"X-B3-Flags",
 		"X-Ot-Span-Context",
+		RecordCallTreeHeader,
 	}
 	forwardableHeadersSet = make(map[string]bool, len(forwardableHeaders))
 )
@@ -0,0 +1,88 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errQueueFull is returned by acquire when the queue of the limiter is full.
+var errQueueFull = errors.New("request queue is full")
+
+// limiter bounds the number of requests served concurrently. Requests beyond
+// the limit wait in a bounded queue, and are rejected once it is full.
+type limiter struct {
+	// slots holds a token per request being served.
+	slots chan struct{}
+	// queue holds a token per request waiting for a slot.
+	queue chan struct{}
+}
+
+// newLimiter returns a limiter serving up to maxConcurrency requests at once,
+// with up to queueSize more waiting. It returns nil, which admits every
+// request, if maxConcurrency is not positive.
+func newLimiter(maxConcurrency int32, queueSize int32) *limiter {
+	if maxConcurrency <= 0 {
+		return nil
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	return &limiter{
+		slots: make(chan struct{}, maxConcurrency),
+		queue: make(chan struct{}, queueSize),
+	}
+}
+
+// acquire waits for a slot to serve a request, returning how long the request
+// was queued. It returns errQueueFull if the queue is full, or the error of
+// ctx if it is done before a slot frees up. A successful acquire must be
+// followed by a release.
+func (l *limiter) acquire(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return 0, nil
+	default:
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		return 0, errQueueFull
+	}
+	defer func() { <-l.queue }()
+
+	start := time.Now()
+	select {
+	case l.slots <- struct{}{}:
+		return time.Since(start), nil
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	}
+}
+
+// release frees the slot taken by a successful acquire.
+func (l *limiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
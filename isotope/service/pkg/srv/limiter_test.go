@@ -0,0 +1,106 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Acquire(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxConcurrency int32
+		queueSize      int32
+		// held is the number of slots taken before acquiring.
+		held int
+		// release frees a held slot after the delay, if set.
+		release time.Duration
+		// cancel cancels the context after the delay, if set.
+		cancel time.Duration
+		err    error
+		queued bool
+	}{
+		{name: "unlimited", maxConcurrency: 0, held: 0},
+		{name: "free slot", maxConcurrency: 2, held: 1},
+		{name: "no queue", maxConcurrency: 1, queueSize: 0, held: 1, err: errQueueFull},
+		{
+			name: "queued", maxConcurrency: 1, queueSize: 1, held: 1,
+			release: 10 * time.Millisecond, queued: true,
+		},
+		{
+			name: "canceled", maxConcurrency: 1, queueSize: 1, held: 1,
+			cancel: 10 * time.Millisecond, err: context.Canceled, queued: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			l := newLimiter(test.maxConcurrency, test.queueSize)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			for i := 0; i < test.held; i++ {
+				if _, err := l.acquire(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if test.release > 0 {
+				time.AfterFunc(test.release, l.release)
+			}
+			if test.cancel > 0 {
+				time.AfterFunc(test.cancel, cancel)
+			}
+
+			queueDuration, err := l.acquire(ctx)
+			if test.err != err {
+				t.Errorf("expected %v; actual %v", test.err, err)
+			}
+			if queued := queueDuration > 0; test.queued != queued {
+				t.Errorf("expected queued %v; actual %v", test.queued, queueDuration)
+			}
+		})
+	}
+}
+
+func TestLimiter_QueueFull(t *testing.T) {
+	l := newLimiter(1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := l.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second request waits in the queue, so the third is rejected.
+	queued := make(chan error)
+	go func() {
+		_, err := l.acquire(ctx)
+		queued <- err
+	}()
+	for len(l.queue) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := l.acquire(ctx); err != errQueueFull {
+		t.Errorf("expected %v; actual %v", errQueueFull, err)
+	}
+
+	l.release()
+	if err := <-queued; err != nil {
+		t.Errorf("expected %v; actual %v", nil, err)
+	}
+}
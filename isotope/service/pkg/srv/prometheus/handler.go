@@ -58,6 +58,38 @@ var (
 			Buckets: durationBuckets,
 		}, []string{"code"})
 
+	serviceQueueWaitSeconds = prom.NewHistogram(
+		prom.HistogramOpts{
+			Name:    "service_queue_wait_seconds",
+			Help:    "Duration in seconds requests waited for a free slot when the concurrency of this service is limited.",
+			Buckets: durationBuckets,
+		})
+
+	serviceRejectedRequestsTotal = prom.NewCounter(
+		prom.CounterOpts{
+			Name: "service_rejected_requests_total",
+			Help: "Number of requests rejected because the queue of this service was full.",
+		})
+
+	serviceCacheLookupsTotal = prom.NewCounterVec(
+		prom.CounterOpts{
+			Name: "service_cache_lookups_total",
+			Help: "Number of lookups of this cache service, by result (hit or miss).",
+		}, []string{"result"})
+
+	serviceDatabaseConnectionWaitSeconds = prom.NewHistogram(
+		prom.HistogramOpts{
+			Name:    "service_database_connection_wait_seconds",
+			Help:    "Duration in seconds queries of this database service waited for a connection.",
+			Buckets: durationBuckets,
+		})
+
+	serviceWorkQueueDepth = prom.NewGauge(
+		prom.GaugeOpts{
+			Name: "service_work_queue_depth",
+			Help: "Number of requests accepted but not processed yet by this queue service.",
+		})
+
 	serviceResponseSize = prom.NewHistogramVec(
 		prom.HistogramOpts{
 			Name:    "service_response_size",
@@ -77,6 +109,13 @@ func Handler() http.Handler {
 	prom.MustRegister(serviceRequestDurationSeconds)
 	prom.MustRegister(serviceResponseSize)
 
+	prom.MustRegister(serviceQueueWaitSeconds)
+	prom.MustRegister(serviceRejectedRequestsTotal)
+
+	prom.MustRegister(serviceCacheLookupsTotal)
+	prom.MustRegister(serviceDatabaseConnectionWaitSeconds)
+	prom.MustRegister(serviceWorkQueueDepth)
+
 	return promhttp.Handler()
 }
 
@@ -102,3 +141,35 @@ func RecordResponseSent(duration time.Duration, size int, code int) {
 		duration.Seconds())
 	serviceResponseSize.WithLabelValues(strCode).Observe(float64(size))
 }
+
+// RecordQueueWait observes the time a request waited for a free slot.
+func RecordQueueWait(duration time.Duration) {
+	serviceQueueWaitSeconds.Observe(duration.Seconds())
+}
+
+// RecordRequestRejected increments the Prometheus counter for requests
+// rejected because the queue was full.
+func RecordRequestRejected() {
+	serviceRejectedRequestsTotal.Inc()
+}
+
+// RecordCacheLookup increments the Prometheus counter for cache hits or
+// misses.
+func RecordCacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	serviceCacheLookupsTotal.WithLabelValues(result).Inc()
+}
+
+// RecordDatabaseConnectionWait observes the time a query waited for a
+// connection.
+func RecordDatabaseConnectionWait(duration time.Duration) {
+	serviceDatabaseConnectionWaitSeconds.Observe(duration.Seconds())
+}
+
+// SetWorkQueueDepth sets the number of requests waiting to be processed.
+func SetWorkQueueDepth(depth int) {
+	serviceWorkQueueDepth.Set(float64(depth))
+}
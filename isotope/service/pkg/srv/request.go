@@ -25,16 +25,22 @@ import (
 	"istio.io/tools/isotope/convert/pkg/graph/size"
 )
 
+// sendRequest sends a request of size bytes to the service at destHost, i.e.
+// its name within the same namespace or else its fully qualified domain name.
+// With newConnection, the request does not reuse an idle connection and its
+// connection is closed afterwards.
 func sendRequest(
-	destName string,
+	destHost string,
 	size size.ByteSize,
-	requestHeader http.Header) (*http.Response, error) {
-	url := fmt.Sprintf("http://%s:%v", destName, consts.ServicePort)
+	requestHeader http.Header,
+	newConnection bool) (*http.Response, error) {
+	url := fmt.Sprintf("http://%s:%v", destHost, consts.ServicePort)
 	request, err := buildRequest(url, size, requestHeader)
 	if err != nil {
 		return nil, err
 	}
-	log.Debugf("sending request to %s (%s)", destName, url)
+	request.Close = newConnection
+	log.Debugf("sending request to %s (%s)", destHost, url)
 	return http.DefaultClient.Do(request)
 }
 
@@ -0,0 +1,215 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"istio.io/pkg/log"
+
+	"istio.io/tools/isotope/convert/pkg/consts"
+	"istio.io/tools/isotope/convert/pkg/graph/size"
+	"istio.io/tools/isotope/service/pkg/srv/prometheus"
+)
+
+// TCP services speak a length-prefixed request/response protocol. Each
+// message is a frame of a big-endian uint16 status (an HTTP status code in
+// responses, 0 in requests), a big-endian uint32 payload length and the
+// payload. A connection carries any number of requests, one at a time.
+const (
+	frameHeaderSize = 6
+
+	// maxFramePayloadSize bounds the payloads read, so a corrupt frame does
+	// not allocate gigabytes.
+	maxFramePayloadSize = 1 << 30
+)
+
+func writeFrame(w io.Writer, status int, payload []byte) error {
+	var header [frameHeaderSize]byte
+	binary.BigEndian.PutUint16(header[:2], uint16(status))
+	binary.BigEndian.PutUint32(header[2:], uint32(len(payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame reads a frame from r, discarding its payload and returning its
+// status and the size of its payload.
+func readFrame(r io.Reader) (status int, n int64, err error) {
+	var header [frameHeaderSize]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	status = int(binary.BigEndian.Uint16(header[:2]))
+	length := binary.BigEndian.Uint32(header[2:])
+	if length > maxFramePayloadSize {
+		err = fmt.Errorf("frame payload of %d bytes exceeds %d", length, maxFramePayloadSize)
+		return
+	}
+	n, err = io.CopyN(ioutil.Discard, r, int64(length))
+	return
+}
+
+// ServeTCP accepts connections on listener and answers each request on them
+// as ServeHTTP does.
+func (h Handler) ServeTCP(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go h.serveConn(conn)
+	}
+}
+
+func (h Handler) serveConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		if _, _, err := readFrame(reader); err != nil {
+			if err != io.EOF {
+				log.Errorf("%s", err)
+			}
+			return
+		}
+		startTime := time.Now()
+
+		prometheus.RecordRequestReceived()
+
+		status, responsePayload := h.handle(context.Background(), startTime, http.Header{}, nil)
+
+		err := writeFrame(writer, status, responsePayload)
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			log.Errorf("%s", err)
+			return
+		}
+
+		stopTime := time.Now()
+		duration := stopTime.Sub(startTime)
+		prometheus.RecordResponseSent(duration, len(responsePayload), status)
+	}
+}
+
+// tcpConnections holds the idle connections to TCP services.
+var tcpConnections = &connectionPool{
+	idle:                map[string][]net.Conn{},
+	maxIdleConnsPerHost: http.DefaultMaxIdleConnsPerHost,
+}
+
+// SetMaxIdleTCPConnectionsPerHost sets the number of idle connections kept to
+// each TCP service. Zero keeps the default of HTTP, i.e. 2.
+func SetMaxIdleTCPConnectionsPerHost(n int) {
+	if n <= 0 {
+		n = http.DefaultMaxIdleConnsPerHost
+	}
+	tcpConnections.mu.Lock()
+	tcpConnections.maxIdleConnsPerHost = n
+	tcpConnections.mu.Unlock()
+}
+
+// connectionPool keeps connections idle between requests, like the HTTP
+// keep-alive connections of http.Transport.
+type connectionPool struct {
+	mu                  sync.Mutex
+	idle                map[string][]net.Conn
+	maxIdleConnsPerHost int
+}
+
+// get returns an idle connection to addr, if there is one.
+func (p *connectionPool) get(addr string) net.Conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conns := p.idle[addr]
+	if len(conns) == 0 {
+		return nil
+	}
+	conn := conns[len(conns)-1]
+	p.idle[addr] = conns[:len(conns)-1]
+	return conn
+}
+
+// put keeps conn idle for the next request to addr, closing it if enough
+// connections to addr are idle.
+func (p *connectionPool) put(addr string, conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle[addr]) >= p.maxIdleConnsPerHost {
+		conn.Close()
+		return
+	}
+	p.idle[addr] = append(p.idle[addr], conn)
+}
+
+// sendTCPRequest sends a request of size bytes to the TCP service at destHost
+// and returns the status of its response. Unless newConnection is set, the
+// connection is taken from and returned to the pool of idle connections.
+func sendTCPRequest(
+	destHost string, size size.ByteSize, newConnection bool) (int, error) {
+	payload, err := makeRandomByteArray(size)
+	if err != nil {
+		return 0, err
+	}
+	addr := fmt.Sprintf("%s:%v", destHost, consts.ServicePort)
+	log.Debugf("sending TCP request to %s", addr)
+
+	if !newConnection {
+		if conn := tcpConnections.get(addr); conn != nil {
+			status, err := exchange(conn, payload)
+			if err == nil {
+				tcpConnections.put(addr, conn)
+				return status, nil
+			}
+			// The service may have closed the idle connection, so the request
+			// is retried once on a new one.
+			conn.Close()
+		}
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	status, err := exchange(conn, payload)
+	if err != nil || newConnection {
+		conn.Close()
+	} else {
+		tcpConnections.put(addr, conn)
+	}
+	return status, err
+}
+
+// exchange writes a request with payload to conn and reads the response.
+func exchange(conn net.Conn, payload []byte) (int, error) {
+	if err := writeFrame(conn, 0, payload); err != nil {
+		return 0, err
+	}
+	status, _, err := readFrame(conn)
+	return status, err
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this currentFile except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"istio.io/tools/isotope/convert/pkg/graph/svc"
+)
+
+func TestFrames(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		payload []byte
+	}{
+		{"request", 0, []byte("request")},
+		{"response", http.StatusOK, []byte("response")},
+		{"empty", http.StatusServiceUnavailable, nil},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var b bytes.Buffer
+			if err := writeFrame(&b, test.status, test.payload); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := frameHeaderSize+len(test.payload), b.Len(); expected != actual {
+				t.Errorf("expected %v; actual %v", expected, actual)
+			}
+			status, n, err := readFrame(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.status != status {
+				t.Errorf("expected %v; actual %v", test.status, status)
+			}
+			if int64(len(test.payload)) != n {
+				t.Errorf("expected %v; actual %v", len(test.payload), n)
+			}
+		})
+	}
+}
+
+func TestReadFrame_Invalid(t *testing.T) {
+	oversized := make([]byte, frameHeaderSize)
+	binary.BigEndian.PutUint32(oversized[2:], maxFramePayloadSize+1)
+	truncated := make([]byte, frameHeaderSize, frameHeaderSize+1)
+	binary.BigEndian.PutUint32(truncated[2:], 2)
+	truncated = append(truncated, 'x')
+
+	tests := []struct {
+		name  string
+		frame []byte
+		err   error
+	}{
+		{"no frame", nil, io.EOF},
+		{"partial header", []byte{0, 200}, io.ErrUnexpectedEOF},
+		{"truncated payload", truncated, io.EOF},
+		{"oversized payload", oversized, nil},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := readFrame(bytes.NewReader(test.frame))
+			if err == nil {
+				t.Fatal("expected an error; actual nil")
+			}
+			if test.err != nil && test.err != err {
+				t.Errorf("expected %v; actual %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestConnectionPool(t *testing.T) {
+	p := &connectionPool{idle: map[string][]net.Conn{}, maxIdleConnsPerHost: 1}
+	if conn := p.get("a:8080"); conn != nil {
+		t.Errorf("expected no idle connection; actual %v", conn)
+	}
+
+	kept, keptPeer := net.Pipe()
+	defer keptPeer.Close()
+	extra, extraPeer := net.Pipe()
+	defer extraPeer.Close()
+	p.put("a:8080", kept)
+	p.put("a:8080", extra)
+
+	if conn := p.get("b:8080"); conn != nil {
+		t.Errorf("expected no idle connection to b; actual %v", conn)
+	}
+	if conn := p.get("a:8080"); conn != kept {
+		t.Errorf("expected %v; actual %v", kept, conn)
+	}
+	if conn := p.get("a:8080"); conn != nil {
+		t.Errorf("expected the extra connection to be closed; actual %v", conn)
+	}
+	// The connection beyond the idle limit was closed.
+	if _, err := extraPeer.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("expected %v; actual %v", io.EOF, err)
+	}
+	kept.Close()
+}
+
+// TestServeTCP sends several requests on a single connection, as callers do
+// with the connections of their pool.
+func TestServeTCP(t *testing.T) {
+	h, err := newHandler(svc.Service{Name: "a", ResponseSize: 3}, "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() { _ = h.ServeTCP(listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 3; i++ {
+		if err := writeFrame(conn, 0, []byte("request")); err != nil {
+			t.Fatal(err)
+		}
+		status, n, err := readFrame(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != http.StatusOK || n != 3 {
+			t.Errorf("expected %v with 3 bytes; actual %v with %v", http.StatusOK, status, n)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// PathMatcher returns true if a rule applies to the file at the absolute path.
+type PathMatcher func(path string) bool
+
+// NewAnalyzer returns a go/analysis analyzer named after the ID of rule, which
+// checks rule against the files of the package matched by match and reports
+// its lint errors as diagnostics. This lets the rules run in the drivers of
+// analyzers, such as go vet -vettool, golangci-lint or gopls.
+func NewAnalyzer(rule Rule, doc string, match PathMatcher) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: rule.GetID(),
+		Doc:  doc,
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			for _, file := range pass.Files {
+				tf := pass.Fset.File(file.Pos())
+				if tf == nil || !match(tf.Name()) {
+					continue
+				}
+				report := &Report{reportf: func(f Finding) {
+					pass.Report(analysis.Diagnostic{
+						Pos:            posIn(tf, f.Pos),
+						Category:       f.RuleID,
+						Message:        f.Message,
+						SuggestedFixes: suggestedFixes(tf, f.Fix),
+					})
+				}}
+				v := FileVisitor{
+					path:      tf.Name(),
+					rules:     []Rule{rule},
+					whitelist: NewWhitelist(nil),
+					fileset:   pass.Fset,
+					info:      pass.TypesInfo,
+					report:    report,
+				}
+				v.walk(file)
+			}
+			return nil, nil
+		},
+	}
+}
+
+// posIn returns the position pos of the file tf, or the start of tf if pos is
+// not in it, e.g. when moved by a line directive.
+func posIn(tf *token.File, pos token.Position) token.Pos {
+	if pos.Filename != tf.Name() || pos.Line < 1 || pos.Line > tf.LineCount() {
+		return token.Pos(tf.Base())
+	}
+	return tf.LineStart(pos.Line) + token.Pos(pos.Column-1)
+}
+
+// suggestedFixes returns fix as suggested fixes of an analysis diagnostic in
+// the file tf, which cannot add imports.
+func suggestedFixes(tf *token.File, fix *SuggestedFix) []analysis.SuggestedFix {
+	if fix == nil || len(fix.AddImports) > 0 {
+		return nil
+	}
+	var edits []analysis.TextEdit
+	for _, e := range fix.Edits {
+		if e.Offset < 0 || e.End < e.Offset || e.End > tf.Size() {
+			return nil
+		}
+		edits = append(edits, analysis.TextEdit{
+			Pos:     tf.Pos(e.Offset),
+			End:     tf.Pos(e.End),
+			NewText: []byte(e.NewText),
+		})
+	}
+	return []analysis.SuggestedFix{{Message: fix.Message, TextEdits: edits}}
+}
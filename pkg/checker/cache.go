@@ -0,0 +1,199 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// cacheVersion is changed whenever the findings of unchanged files and rules
+// may differ, e.g. when the format of the cache entries changes.
+const cacheVersion = "1"
+
+// cacheKey returns the key of the findings of the rules on the file at path
+// in the cache, which is a hash of the contents of the file, of the rules and
+// of everything else the findings depend on, or false if the file cannot be
+// read. The rules which need the type information depend on the package of
+// the file and its dependencies too, which hashes by directory are deps; the
+// findings of files without one are not cached.
+func cacheKey(path string, rules []Rule, whitelist *Whitelist, deps map[string]string) (string, bool) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%v\x00", cacheVersion, path, ReportUnusedSuppressions)
+	typed := false
+	for _, rule := range rules {
+		fmt.Fprintf(h, "%T%+v\x00%v\x00", rule, rule, whitelist.Apply(path, rule))
+		if _, ok := rule.(TypedRule); ok {
+			typed = true
+		}
+	}
+	if typed {
+		key, ok := deps[filepath.Dir(path)]
+		if !ok {
+			return "", false
+		}
+		fmt.Fprintf(h, "%s\x00", key)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// depsKeys loads the packages in dirs, including their tests, and returns by
+// directory a hash of the go files of its packages and of their dependencies
+// outside of the standard library, which is assumed not to change. There is
+// none for the directories which packages cannot be loaded.
+func depsKeys(dirs []string) map[string]string {
+	keys := map[string]string{}
+	if len(dirs) == 0 {
+		return keys
+	}
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+		Tests: true,
+		Dir:   dirs[0],
+	}
+	pkgs, err := packages.Load(cfg, dirs...)
+	if err != nil {
+		return keys
+	}
+	roots := map[string][]*packages.Package{}
+	for _, pkg := range pkgs {
+		// The generated main packages of the tests are left out.
+		if len(pkg.GoFiles) > 0 && !strings.HasSuffix(pkg.ID, ".test") {
+			dir := filepath.Dir(pkg.GoFiles[0])
+			roots[dir] = append(roots[dir], pkg)
+		}
+	}
+
+	goroot := filepath.Join(build.Default.GOROOT, "src") + string(filepath.Separator)
+	hashes := map[string]string{}
+	for dir, pkgs := range roots {
+		deps := map[string]*packages.Package{}
+		var visit func(pkg *packages.Package)
+		visit = func(pkg *packages.Package) {
+			if deps[pkg.ID] != nil || len(pkg.GoFiles) > 0 && strings.HasPrefix(pkg.GoFiles[0], goroot) {
+				return
+			}
+			deps[pkg.ID] = pkg
+			for _, imp := range pkg.Imports {
+				visit(imp)
+			}
+		}
+		for _, pkg := range pkgs {
+			visit(pkg)
+		}
+		var ids []string
+		for id := range deps {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		h := sha256.New()
+		ok := true
+		for _, id := range ids {
+			hash, found := hashes[id]
+			if !found {
+				hash = packageHash(deps[id])
+				hashes[id] = hash
+			}
+			if hash == "" {
+				ok = false
+				break
+			}
+			fmt.Fprintf(h, "%s\x00%s\x00", id, hash)
+		}
+		if ok {
+			keys[dir] = hex.EncodeToString(h.Sum(nil))
+		}
+	}
+	return keys
+}
+
+// packageHash returns a hash of the go files of pkg, or "" if it has errors or
+// its files cannot be read.
+func packageHash(pkg *packages.Package) string {
+	if len(pkg.Errors) > 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, file := range pkg.GoFiles {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return ""
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", file, len(content))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// readCache returns the findings with the key in the cache at CacheDir, or
+// false if there are none.
+func readCache(key string) ([]Finding, bool) {
+	b, err := ioutil.ReadFile(filepath.Join(CacheDir, key[:2], key))
+	if err != nil {
+		return nil, false
+	}
+	var findings []Finding
+	if err := json.Unmarshal(b, &findings); err != nil {
+		return nil, false
+	}
+	return findings, true
+}
+
+// writeCache stores the findings with the key in the cache at CacheDir. The
+// cache is only an optimization, so failures to write it are ignored, and the
+// file is checked again next time.
+func writeCache(key string, findings []Finding) {
+	b, err := json.Marshal(findings)
+	if err != nil {
+		return
+	}
+	dir := filepath.Join(CacheDir, key[:2])
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	// The entry is renamed into place, so that concurrent linters never read
+	// a partial entry.
+	tmp, err := ioutil.TempFile(dir, key+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(dir, key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
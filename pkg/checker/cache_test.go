@@ -0,0 +1,96 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type typedIDRule struct{ idRule }
+
+func (r typedIDRule) CheckTyped(ast.Node, *types.Info, *token.FileSet, *Report) {}
+
+func writeModule(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCacheKeyDeps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeModule(t, dir, map[string]string{
+		"go.mod":         "module example.com/m\n",
+		"a/a.go":         "package a\n\nimport \"example.com/m/dep\"\n\nvar A = dep.Name\n",
+		"a/a_test.go":    "package a\n\nimport \"example.com/m/testdep\"\n\nvar _ = testdep.Name\n",
+		"dep/dep.go":     "package dep\n\nimport \"example.com/m/deeper\"\n\nconst Name = deeper.Name\n",
+		"deeper/d.go":    "package deeper\n\nconst Name = \"A\"\n",
+		"testdep/t.go":   "package testdep\n\nconst Name = \"T\"\n",
+		"other/other.go": "package other\n",
+		"broken/b.go":    "package broken\n\nimport \"example.com/m/missing\"\n\nvar _ = missing.Name\n",
+	})
+	path := filepath.Join(dir, "a", "a.go")
+	typed := []Rule{typedIDRule{"no_os_env"}}
+	key := func() string {
+		deps := depsKeys([]string{filepath.Join(dir, "a")})
+		k, ok := cacheKey(path, typed, NewWhitelist(nil), deps)
+		if !ok {
+			t.Fatalf("expected a cache key of %s", path)
+		}
+		return k
+	}
+
+	initial := key()
+	writeModule(t, dir, map[string]string{"other/other.go": "package other\n\nconst Name = \"O\"\n"})
+	if actual := key(); actual != initial {
+		t.Errorf("expected the key to ignore packages which are not dependencies")
+	}
+	for _, change := range []string{"deeper/d.go", "testdep/t.go", "a/a_test.go"} {
+		previous := key()
+		content, err := ioutil.ReadFile(filepath.Join(dir, change))
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeModule(t, dir, map[string]string{change: string(content) + "\nconst Changed = true\n"})
+		if actual := key(); actual == previous {
+			t.Errorf("expected the key to change with %s", change)
+		}
+	}
+
+	brokenPath := filepath.Join(dir, "broken", "b.go")
+	deps := depsKeys([]string{filepath.Join(dir, "broken")})
+	if _, ok := cacheKey(brokenPath, typed, NewWhitelist(nil), deps); ok {
+		t.Errorf("expected no cache key of a file which package cannot be loaded")
+	}
+	if _, ok := cacheKey(brokenPath, []Rule{idRule("no_sleep")}, NewWhitelist(nil), deps); !ok {
+		t.Errorf("expected a cache key of a file checked by untyped rules only")
+	}
+}
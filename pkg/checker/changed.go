@@ -0,0 +1,67 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ChangedFiles returns the absolute paths of the files of the git repository
+// of the directory dir which changed since the git ref, i.e. the files added,
+// copied, modified or renamed since ref, committed or not, and the untracked
+// files which are not ignored.
+func ChangedFiles(dir string, ref string) (map[string]bool, error) {
+	// The root of the repository is found relative to dir, so that the paths
+	// keep the symbolic links of dir.
+	cdup, err := git(dir, "rev-parse", "--show-cdup")
+	if err != nil {
+		return nil, err
+	}
+	top := filepath.Join(dir, strings.TrimSpace(cdup))
+	diff, err := git(top, "diff", "--name-only", "--diff-filter=ACMR", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(top, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	changed := map[string]bool{}
+	for _, name := range strings.Split(diff+untracked, "\n") {
+		if name != "" {
+			changed[filepath.Join(top, filepath.FromSlash(name))] = true
+		}
+	}
+	return changed, nil
+}
+
+// git runs git with args in the directory dir, and returns its output.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %v: %s",
+			strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -19,34 +19,90 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
-	"strings"
+	"runtime"
+	"sync"
 )
 
 var (
-	// IgnoreTestLinterData skips over unit tests
+	// Workers is the number of files checked concurrently.
+	Workers = runtime.NumCPU()
+	// ChangedSince, if not empty, is a git ref, and only the files changed
+	// since the ref are checked.
+	ChangedSince string
+	// CacheDir, if not empty, is the directory of the cache of the lint errors
+	// of the files, which are not checked again until they or the rules change.
+	CacheDir string
+
+	// IgnoreTestLinterData skipped the testdata of testlinter.
+	//
+	// Deprecated: the testdata directories are always skipped when walking the
+	// directories of the paths to check, and this has no effect.
 	IgnoreTestLinterData = true
 )
 
-// Check checks the list of files, and write to the given Report.
+// Check checks the list of files, and write to the given Report. The packages
+// of the files are loaded with type information for the TypedRules. The files
+// are checked concurrently, but the lint errors are reported in the order of
+// the files.
+//
+// The directories of paths are walked as the go command does, skipping the
+// vendor and testdata directories and the ones which names begin with . or _,
+// as well as the files ignored by git.
 func Check(paths []string, factory RulesFactory, whitelist *Whitelist, report *Report) error {
 	// Empty paths means current dir.
 	if len(paths) == 0 {
 		paths = []string{"."}
 	}
 
+	var fpaths []string
+	rulesByPath := map[string][]Rule{}
 	for _, path := range paths {
 		if !filepath.IsAbs(path) {
 			path, _ = filepath.Abs(path)
 		}
+		root := path
+		var changed map[string]bool
+		if ChangedSince != "" {
+			dir := root
+			if info, err := os.Stat(root); err == nil && !info.IsDir() {
+				dir = filepath.Dir(root)
+			}
+			var err error
+			if changed, err = ChangedFiles(dir, ChangedSince); err != nil {
+				return err
+			}
+		}
+		ignores := map[string]*gitignore{}
 		err := filepath.Walk(path, func(fpath string, info os.FileInfo, err error) error {
 			if err != nil {
 				return fmt.Errorf("pervent panic by handling failure accessing a path %q: %v", fpath, err)
 			}
+			// The paths to check are never skipped, even if ignored.
+			if fpath == root {
+				if info.IsDir() {
+					ignores[fpath] = loadGitignore(fpath)
+				}
+			} else {
+				parent := ignores[filepath.Dir(fpath)]
+				if info.IsDir() {
+					if skipDir(fpath) || parent.ignored(fpath, true) {
+						return filepath.SkipDir
+					}
+					ignores[fpath] = readGitignore(fpath, parent)
+				} else if parent.ignored(fpath, false) {
+					return nil
+				}
+			}
+			if changed != nil && !changed[fpath] {
+				return nil
+			}
 			rules := factory.GetRules(fpath, info)
 			if len(rules) > 0 {
-				fileCheck(fpath, rules, whitelist, report)
+				fpaths = append(fpaths, fpath)
+				rulesByPath[fpath] = rules
 			}
 			return nil
 		})
@@ -54,31 +110,101 @@ func Check(paths []string, factory RulesFactory, whitelist *Whitelist, report *R
 			return fmt.Errorf("error visiting the path %q: %v", path, err)
 		}
 	}
+
+	// The files which lint errors are cached are not checked.
+	reports := make([]*Report, len(fpaths))
+	keys := make([]string, len(fpaths))
+	var unchecked []int
+	var deps map[string]string
+	if CacheDir != "" && needsTypes(fpaths, rulesByPath) {
+		deps = depsKeys(dirsOf(fpaths))
+	}
+	for i, fpath := range fpaths {
+		if CacheDir != "" {
+			if key, ok := cacheKey(fpath, rulesByPath[fpath], whitelist, deps); ok {
+				keys[i] = key
+				if findings, ok := readCache(key); ok {
+					reports[i] = &Report{findings: findings}
+					continue
+				}
+			}
+		}
+		unchecked = append(unchecked, i)
+	}
+
+	typedFiles := map[string]*typedFile{}
+	var uncheckedPaths []string
+	for _, i := range unchecked {
+		uncheckedPaths = append(uncheckedPaths, fpaths[i])
+	}
+	if needsTypes(uncheckedPaths, rulesByPath) {
+		typedFiles = loadTypedFiles(dirsOf(uncheckedPaths))
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < Workers || w == 0; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fpath := fpaths[i]
+				reports[i] = NewLintReport()
+				fileCheck(fpath, rulesByPath[fpath], whitelist, reports[i], typedFiles[fpath])
+				if keys[i] != "" {
+					writeCache(keys[i], reports[i].findings)
+				}
+			}
+		}()
+	}
+	for _, i := range unchecked {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, r := range reports {
+		for _, f := range r.findings {
+			report.AddFinding(f)
+		}
+	}
 	return nil
 }
 
-// fileCheck checks a file using the given rules, and write to the given Report.
-func fileCheck(path string, rules []Rule, whitelist *Whitelist, report *Report) {
-	// TODO: skip over linter tests in a principled manner for all linters
-	if IgnoreTestLinterData && strings.Contains(path, "testlinter/testdata") {
-		return
+// needsTypes returns true if any of the rules of the files at fpaths is a
+// TypedRule.
+func needsTypes(fpaths []string, rulesByPath map[string][]Rule) bool {
+	for _, fpath := range fpaths {
+		for _, rule := range rulesByPath[fpath] {
+			if _, ok := rule.(TypedRule); ok {
+				return true
+			}
+		}
 	}
+	return false
+}
 
-	fs := token.NewFileSet()
-	astFile, err := parser.ParseFile(fs, path, nil, parser.Mode(0))
-	if err != nil {
-		report.AddString(fmt.Sprintf("%v", err))
-		return
+// fileCheck checks a file using the given rules, and write to the given Report.
+// The file is parsed unless typed holds it with its type information.
+func fileCheck(path string, rules []Rule, whitelist *Whitelist, report *Report, typed *typedFile) {
+	if typed == nil {
+		fs := token.NewFileSet()
+		astFile, err := parser.ParseFile(fs, path, nil, parser.ParseComments)
+		if err != nil {
+			report.AddString(fmt.Sprintf("%v", err))
+			return
+		}
+		typed = &typedFile{fileset: fs, file: astFile}
 	}
 	v := FileVisitor{
 		path:      path,
 		rules:     rules,
 		whitelist: whitelist,
-		fileset:   fs,
+		fileset:   typed.fileset,
+		info:      typed.info,
 		report:    report,
 	}
-	// Walk through the files
-	ast.Walk(&v, astFile)
+	v.walk(typed.file)
 }
 
 // FileVisitor visits the go file syntax tree and applies the given rules.
@@ -87,7 +213,27 @@ type FileVisitor struct {
 	rules     []Rule     // rules to check
 	whitelist *Whitelist // rules to skip
 	fileset   *token.FileSet
-	report    *Report // report for linting process
+	info      *types.Info // type information of the file, if available
+	report    *Report     // report for linting process
+	// ruleReport is the report given to the rules, which drops the lint
+	// errors suppressed by the lint:ignore directives of the file.
+	ruleReport *Report
+}
+
+// walk walks through the syntax tree of file and applies the rules, honouring
+// the lint:ignore and lint:file-ignore directives of file.
+func (fv *FileVisitor) walk(file *ast.File) {
+	suppressions := parseSuppressions(file, fv.fileset)
+	fv.ruleReport = suppressionReport(suppressions, fv.report)
+	ast.Walk(fv, file)
+
+	ids := map[string]bool{}
+	for _, rule := range fv.rules {
+		if !fv.whitelist.Apply(fv.path, rule) {
+			ids[rule.GetID()] = true
+		}
+	}
+	reportSuppressions(suppressions, ids, fv.report)
 }
 
 // Visit checks each node and runs the applicable checks.
@@ -98,8 +244,13 @@ func (fv *FileVisitor) Visit(node ast.Node) ast.Visitor {
 
 	// ApplyRules applies rules to node and generate lint report.
 	for _, rule := range fv.rules {
-		if !fv.whitelist.Apply(fv.path, rule) {
-			rule.Check(node, fv.fileset, fv.report)
+		if fv.whitelist.Apply(fv.path, rule) {
+			continue
+		}
+		if typedRule, ok := rule.(TypedRule); ok {
+			typedRule.CheckTyped(node, fv.info, fv.fileset, fv.ruleReport)
+		} else {
+			rule.Check(node, fv.fileset, fv.ruleReport)
 		}
 	}
 	return fv
@@ -0,0 +1,192 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes of a hunk.
+const diffContext = 3
+
+// WriteDiff writes the changes from before to after of the file named name to
+// w as a unified diff, or nothing if there are none.
+func WriteDiff(w io.Writer, name string, before []byte, after []byte) error {
+	a := splitLines(string(before))
+	b := splitLines(string(after))
+	ops := diffLines(a, b)
+
+	var hunks [][]diffOp
+	var hunk []diffOp
+	for i, op := range ops {
+		if op.kind != diffEqual {
+			hunk = append(hunk, op)
+			continue
+		}
+		// Keep the unchanged lines close enough to a change.
+		near := false
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(ops) && ops[j].kind != diffEqual {
+				near = true
+				break
+			}
+		}
+		if near {
+			hunk = append(hunk, op)
+		} else if len(hunk) > 0 {
+			hunks = append(hunks, hunk)
+			hunk = nil
+		}
+	}
+	if len(hunk) > 0 {
+		hunks = append(hunks, hunk)
+	}
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", name, name); err != nil {
+		return err
+	}
+	for _, hunk := range hunks {
+		var aLen, bLen int
+		for _, op := range hunk {
+			if op.kind != diffInsert {
+				aLen++
+			}
+			if op.kind != diffDelete {
+				bLen++
+			}
+		}
+		if _, err := fmt.Fprintf(w, "@@ -%s +%s @@\n",
+			hunkRange(hunk[0].a, aLen), hunkRange(hunk[0].b, bLen)); err != nil {
+			return err
+		}
+		for _, op := range hunk {
+			line := op.line
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n\\ No newline at end of file\n"
+			}
+			if _, err := io.WriteString(w, string(op.kind)+line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hunkRange formats the range of count lines from the index start.
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type diffKind byte
+
+const (
+	diffEqual  diffKind = ' '
+	diffDelete diffKind = '-'
+	diffInsert diffKind = '+'
+)
+
+// diffOp keeps, deletes or inserts line, at the index a of the lines before
+// and b of the lines after.
+type diffOp struct {
+	kind diffKind
+	line string
+	a, b int
+}
+
+// diffLines returns the shortest edit script from the lines a to the lines b,
+// computed with the Myers algorithm.
+func diffLines(a []string, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			trace = append(trace, v)
+			break
+		}
+	}
+
+	// Backtrack from the end through the furthest reaching paths.
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 2; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{diffEqual, a[x], x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, diffOp{diffInsert, b[y], x, y})
+			} else {
+				x--
+				ops = append(ops, diffOp{diffDelete, a[x], x, y})
+			}
+		}
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns the lines 1 to n, with the lines in changed replaced.
+func numberedLines(n int, changed map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		line, ok := changed[i]
+		if !ok {
+			line = fmt.Sprint(i)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func TestWriteDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{
+			name:     "no changes",
+			before:   numberedLines(5, nil),
+			after:    numberedLines(5, nil),
+			expected: "",
+		},
+		{
+			name:   "change with context",
+			before: numberedLines(10, nil),
+			after:  numberedLines(10, map[int]string{5: "five"}),
+			expected: `--- f.go
++++ f.go
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name:   "changes six lines apart share a hunk",
+			before: numberedLines(12, nil),
+			after:  numberedLines(12, map[int]string{3: "three", 10: "ten"}),
+			expected: `--- f.go
++++ f.go
+@@ -1,12 +1,12 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+ 7
+ 8
+ 9
+-10
++ten
+ 11
+ 12
+`,
+		},
+		{
+			name:   "changes seven lines apart split the hunks",
+			before: numberedLines(13, nil),
+			after:  numberedLines(13, map[int]string{3: "three", 11: "eleven"}),
+			expected: `--- f.go
++++ f.go
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -8,6 +8,6 @@
+ 8
+ 9
+ 10
+-11
++eleven
+ 12
+ 13
+`,
+		},
+		{
+			name:   "insertion and deletion",
+			before: "a\nb\nc\n",
+			after:  "a\nc\nd\n",
+			expected: `--- f.go
++++ f.go
+@@ -1,3 +1,3 @@
+ a
+-b
+ c
++d
+`,
+		},
+		{
+			name:   "insertion into an empty file",
+			before: "",
+			after:  "a\n",
+			expected: `--- f.go
++++ f.go
+@@ -0,0 +1,1 @@
++a
+`,
+		},
+		{
+			name:   "no newline at end of file",
+			before: "a\nb",
+			after:  "a\nc",
+			expected: `--- f.go
++++ f.go
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+`,
+		},
+		{
+			name:   "newline added at end of file",
+			before: "a",
+			after:  "a\n",
+			expected: `--- f.go
++++ f.go
+@@ -1,1 +1,1 @@
+-a
+\ No newline at end of file
++a
+`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var b bytes.Buffer
+			if err := WriteDiff(&b, "f.go", []byte(test.before), []byte(test.after)); err != nil {
+				t.Fatal(err)
+			}
+			if actual := b.String(); actual != test.expected {
+				t.Errorf("expected:\n%s\nactual:\n%s", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MatchGlob returns true if path matches the glob pattern, in which * and ?
+// match any sequence of characters and any character except /, and ** matches
+// any sequence of directories, e.g. tests/**/*_test.go.
+func MatchGlob(pattern string, path string) bool {
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(filepath.ToSlash(path))
+}
+
+// globRegexp returns the regular expression matching the same paths as the
+// glob pattern.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	pattern = filepath.ToSlash(pattern)
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// Exclusions excludes rules from the files matching path globs, as configured
+// in the configuration files of the linters.
+type Exclusions struct {
+	// ids maps each glob, relative to the directory of the configuration,
+	// to the IDs of the rules excluded from its files, * for all.
+	ids map[string][]string
+}
+
+// NewExclusions returns the exclusions of the rules with the IDs in exclude
+// from the files matching its globs. Relative globs are relative to dir.
+func NewExclusions(dir string, exclude map[string][]string) *Exclusions {
+	e := &Exclusions{ids: map[string][]string{}}
+	for glob, ids := range exclude {
+		if !filepath.IsAbs(glob) {
+			glob = filepath.Join(dir, glob)
+		}
+		e.ids[glob] = append(e.ids[glob], ids...)
+	}
+	return e
+}
+
+// Filter returns the rules which are not excluded from the file at path.
+func (e *Exclusions) Filter(path string, rules []Rule) []Rule {
+	if e == nil {
+		return rules
+	}
+	excluded := map[string]bool{}
+	for glob, ids := range e.ids {
+		if MatchGlob(glob, path) {
+			for _, id := range ids {
+				excluded[id] = true
+			}
+		}
+	}
+	if len(excluded) == 0 {
+		return rules
+	}
+	filtered := []Rule{}
+	for _, rule := range rules {
+		if !excluded["*"] && !excluded[rule.GetID()] {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
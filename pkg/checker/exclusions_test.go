@@ -0,0 +1,81 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		matched bool
+	}{
+		{"*_test.go", "a_test.go", true},
+		{"*_test.go", "pkg/a_test.go", false},
+		{"pkg/?.go", "pkg/a.go", true},
+		{"pkg/?.go", "pkg/ab.go", false},
+		{"tests/**/*_test.go", "tests/a_test.go", true},
+		{"tests/**/*_test.go", "tests/e2e/pilot/a_test.go", true},
+		{"tests/**/*_test.go", "pkg/tests/a_test.go", false},
+		{"tests/**", "tests/e2e/a.go", true},
+		{"a.go", "a_go", false},
+		{"/root/pkg/*.go", "/root/pkg/a.go", true},
+	}
+	for _, test := range tests {
+		if actual := MatchGlob(test.pattern, test.path); actual != test.matched {
+			t.Errorf("MatchGlob(%q, %q): expected %v; actual %v", test.pattern, test.path, test.matched, actual)
+		}
+	}
+}
+
+type idRule string
+
+func (r idRule) GetID() string                           { return string(r) }
+func (r idRule) Check(ast.Node, *token.FileSet, *Report) {}
+
+func TestExclusionsFilter(t *testing.T) {
+	rules := []Rule{idRule("no_sleep"), idRule("no_goroutine"), idRule("skip_issue")}
+	e := NewExclusions("/root", map[string][]string{
+		"tests/**/*_test.go":  {"no_sleep"},
+		"tests/e2e/*_test.go": {"no_goroutine"},
+		"/other/*_test.go":    {"*"},
+		"pkg/no_rule_test.go": {},
+	})
+	tests := []struct {
+		path     string
+		expected []Rule
+	}{
+		{"/root/pkg/a_test.go", rules},
+		{"/root/pkg/no_rule_test.go", rules},
+		{"/root/tests/a_test.go", []Rule{idRule("no_goroutine"), idRule("skip_issue")}},
+		{"/root/tests/e2e/a_test.go", []Rule{idRule("skip_issue")}},
+		{"/other/a_test.go", []Rule{}},
+	}
+	for _, test := range tests {
+		if actual := e.Filter(test.path, rules); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %v; actual %v", test.path, test.expected, actual)
+		}
+	}
+
+	var none *Exclusions
+	if actual := none.Filter("/root/tests/a_test.go", rules); !reflect.DeepEqual(actual, rules) {
+		t.Errorf("expected %v; actual %v", rules, actual)
+	}
+}
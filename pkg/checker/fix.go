@@ -0,0 +1,233 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// TextEdit replaces the bytes of a file from Offset to End with NewText, or
+// inserts NewText at Offset if End equals Offset.
+type TextEdit struct {
+	Offset  int
+	End     int
+	NewText string
+}
+
+// SuggestedFix is a change of the file of a lint error which fixes it. The
+// imports left unused by the edits are removed when the fix is applied.
+type SuggestedFix struct {
+	Message string
+	Edits   []TextEdit
+	// AddImports are the import paths the edits need.
+	AddImports []string
+}
+
+// ApplyFixes applies the suggested fixes of the findings to their files, and
+// returns the changed contents of the files, formatted by gofmt, by file
+// name, and the findings which are not fixed. A fix which overlaps a fix of
+// a previous finding is left out, to be applied by running the fixes again.
+func ApplyFixes(findings []Finding) (map[string][]byte, []Finding, error) {
+	var unfixed []Finding
+	var files []string
+	fixes := map[string][]*SuggestedFix{}
+	for _, f := range findings {
+		if f.Fix == nil || f.Pos.Filename == "" {
+			unfixed = append(unfixed, f)
+			continue
+		}
+		if _, ok := fixes[f.Pos.Filename]; !ok {
+			files = append(files, f.Pos.Filename)
+		}
+		fixes[f.Pos.Filename] = append(fixes[f.Pos.Filename], f.Fix)
+	}
+
+	contents := map[string][]byte{}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		fixed, skipped, err := applyFileFixes(file, src, fixes[file])
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to fix %s: %v", file, err)
+		}
+		contents[file] = fixed
+		for _, f := range findings {
+			if f.Fix != nil && skipped[f.Fix] {
+				unfixed = append(unfixed, f)
+			}
+		}
+	}
+	return contents, unfixed, nil
+}
+
+// Fix applies the suggested fixes of the findings of the report to their
+// files, or writes the changes to diff as unified diffs instead if diff is not
+// nil, and returns the report of the findings which are not fixed.
+func (lr *Report) Fix(diff io.Writer) (*Report, error) {
+	contents, unfixed, err := ApplyFixes(lr.findings)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for file := range contents {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if diff != nil {
+			before, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if err := WriteDiff(diff, file, before, contents[file]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(file, contents[file], info.Mode()); err != nil {
+			return nil, err
+		}
+	}
+	return &Report{findings: unfixed}, nil
+}
+
+// applyFileFixes applies fixes to the source src of the file, returning the
+// formatted result and the fixes left out as they overlap previous fixes.
+func applyFileFixes(file string, src []byte, fixes []*SuggestedFix) ([]byte, map[*SuggestedFix]bool, error) {
+	var edits []TextEdit
+	var imports []string
+	skipped := map[*SuggestedFix]bool{}
+	for _, fix := range fixes {
+		if overlaps(fix.Edits, edits, len(src)) {
+			skipped[fix] = true
+			continue
+		}
+		for _, e := range fix.Edits {
+			if !containsEdit(edits, e) {
+				edits = append(edits, e)
+			}
+		}
+		imports = append(imports, fix.AddImports...)
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Offset < edits[j].Offset })
+
+	var b bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		b.Write(src[last:e.Offset])
+		b.WriteString(e.NewText)
+		last = e.End
+	}
+	b.Write(src[last:])
+
+	fs := token.NewFileSet()
+	before, err := parser.ParseFile(fs, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := parser.ParseFile(fs, file, b.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fixes do not compile: %v", err)
+	}
+	for _, path := range imports {
+		astutil.AddImport(fs, after, path)
+	}
+	removeUnusedImports(fs, before, after)
+
+	b.Reset()
+	if err := format.Node(&b, fs, after); err != nil {
+		return nil, nil, err
+	}
+	fixed, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return fixed, skipped, nil
+}
+
+// overlaps returns true if one of edits is out of the file of size bytes, or
+// overlaps with one of the other edits, unless they are the same or both
+// insert text at the same offset, which is inserted in the order of the fixes.
+func overlaps(edits []TextEdit, others []TextEdit, size int) bool {
+	for _, e := range edits {
+		if e.Offset < 0 || e.End < e.Offset || e.End > size {
+			return true
+		}
+		for _, o := range others {
+			if e == o || e.Offset == e.End && o.Offset == o.End {
+				continue
+			}
+			if e.Offset < o.End && o.Offset < e.End || e.Offset == o.Offset {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsEdit(edits []TextEdit, e TextEdit) bool {
+	for _, o := range edits {
+		if o == e {
+			return true
+		}
+	}
+	return false
+}
+
+// removeUnusedImports removes the imports of the file after which were used
+// in the file before the fixes, but are no longer.
+func removeUnusedImports(fs *token.FileSet, before *ast.File, after *ast.File) {
+	deleted := false
+	for _, spec := range after.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || !astutil.UsesImport(before, path) || astutil.UsesImport(after, path) {
+			continue
+		}
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		deleted = astutil.DeleteNamedImport(fs, after, name, path) || deleted
+	}
+	if !deleted {
+		return
+	}
+	// A single import left in parentheses is printed without them, as gofmt
+	// does not remove them.
+	for _, decl := range after.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT && len(gd.Specs) == 1 {
+			gd.Lparen = token.NoPos
+			gd.Rparen = token.NoPos
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"bytes"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fixSource = `package p
+
+import (
+	"os"
+	"time"
+)
+
+func f() {
+	time.Sleep(time.Second)
+	_ = os.Getenv("A")
+}
+`
+
+// edit returns the edit of fixSource replacing old with text.
+func edit(t *testing.T, old string, text string) TextEdit {
+	offset := strings.Index(fixSource, old)
+	if offset < 0 {
+		t.Fatalf("%q not found in the source", old)
+	}
+	return TextEdit{Offset: offset, End: offset + len(old), NewText: text}
+}
+
+func TestApplyFileFixes(t *testing.T) {
+	getenv := `os.Getenv("A")`
+	sleep := "time.Sleep(time.Second)"
+	tests := []struct {
+		name     string
+		fixes    func(t *testing.T) []*SuggestedFix
+		expected string
+		skipped  []int
+	}{
+		{
+			name: "replacement adds and removes imports",
+			fixes: func(t *testing.T) []*SuggestedFix {
+				return []*SuggestedFix{{
+					Edits:      []TextEdit{edit(t, getenv, `env.Get("A")`)},
+					AddImports: []string{"istio.io/pkg/env"},
+				}}
+			},
+			expected: `package p
+
+import (
+	"istio.io/pkg/env"
+	"time"
+)
+
+func f() {
+	time.Sleep(time.Second)
+	_ = env.Get("A")
+}
+`,
+		},
+		{
+			name: "single import left is unparenthesized",
+			fixes: func(t *testing.T) []*SuggestedFix {
+				return []*SuggestedFix{{Edits: []TextEdit{edit(t, getenv, `""`)}}}
+			},
+			expected: `package p
+
+import "time"
+
+func f() {
+	time.Sleep(time.Second)
+	_ = ""
+}
+`,
+		},
+		{
+			name: "separate fixes apply together",
+			fixes: func(t *testing.T) []*SuggestedFix {
+				return []*SuggestedFix{
+					{Edits: []TextEdit{edit(t, sleep, "")}},
+					{Edits: []TextEdit{edit(t, getenv, `""`)}},
+				}
+			},
+			expected: `package p
+
+func f() {
+
+	_ = ""
+}
+`,
+		},
+		{
+			name: "overlapping fix is skipped",
+			fixes: func(t *testing.T) []*SuggestedFix {
+				return []*SuggestedFix{
+					{Edits: []TextEdit{edit(t, getenv, `""`)}},
+					{Edits: []TextEdit{edit(t, `"A"`, `"B"`)}},
+				}
+			},
+			expected: `package p
+
+import "time"
+
+func f() {
+	time.Sleep(time.Second)
+	_ = ""
+}
+`,
+			skipped: []int{1},
+		},
+		{
+			name: "identical edits are applied once",
+			fixes: func(t *testing.T) []*SuggestedFix {
+				return []*SuggestedFix{
+					{Edits: []TextEdit{edit(t, `"A"`, `"B"`)}},
+					{Edits: []TextEdit{edit(t, `"A"`, `"B"`)}},
+				}
+			},
+			expected: strings.Replace(fixSource, `"A"`, `"B"`, 1),
+		},
+		{
+			name: "insertions at the same offset apply in order",
+			fixes: func(t *testing.T) []*SuggestedFix {
+				end := len(fixSource)
+				return []*SuggestedFix{
+					{Edits: []TextEdit{{Offset: end, End: end, NewText: "\nvar a = 1\n"}}},
+					{Edits: []TextEdit{{Offset: end, End: end, NewText: "\nvar b = 2\n"}}},
+				}
+			},
+			expected: fixSource + "\nvar a = 1\n\nvar b = 2\n",
+		},
+		{
+			name: "edit out of the file is skipped",
+			fixes: func(t *testing.T) []*SuggestedFix {
+				return []*SuggestedFix{{Edits: []TextEdit{{Offset: len(fixSource), End: len(fixSource) + 1}}}}
+			},
+			expected: fixSource,
+			skipped:  []int{0},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			fixes := test.fixes(t)
+			fixed, skipped, err := applyFileFixes("p.go", []byte(fixSource), fixes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := string(fixed); actual != test.expected {
+				t.Errorf("expected:\n%s\nactual:\n%s", test.expected, actual)
+			}
+			if len(skipped) != len(test.skipped) {
+				t.Errorf("expected %v skipped fixes; actual %v", len(test.skipped), len(skipped))
+			}
+			for _, i := range test.skipped {
+				if !skipped[fixes[i]] {
+					t.Errorf("expected fix %v to be skipped", i)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyFileFixesNotCompiling(t *testing.T) {
+	fixes := []*SuggestedFix{{Edits: []TextEdit{edit(t, "func f() {", "func f( {")}}}
+	if _, _, err := applyFileFixes("p.go", []byte(fixSource), fixes); err == nil {
+		t.Error("expected an error for fixes which do not compile")
+	}
+}
+
+func TestReportFix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "p.go")
+	if err := ioutil.WriteFile(file, []byte(fixSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := NewLintReport()
+	pos := token.Position{Filename: file, Line: 10, Column: 6}
+	report.AddFix(pos, "no_os_env", "os.Getenv is disallowed", SuggestedFix{
+		Edits: []TextEdit{edit(t, `"A"`, `"B"`)},
+	})
+	report.AddItem(pos, "other", "not fixable")
+
+	var diff bytes.Buffer
+	unfixed, err := report.Fix(&diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedDiff := "--- " + file + "\n+++ " + file + `
+@@ -7,5 +7,5 @@
+ 
+ func f() {
+ 	time.Sleep(time.Second)
+-	_ = os.Getenv("A")
++	_ = os.Getenv("B")
+ }
+`
+	if actual := diff.String(); actual != expectedDiff {
+		t.Errorf("expected:\n%s\nactual:\n%s", expectedDiff, actual)
+	}
+	if src, _ := ioutil.ReadFile(file); string(src) != fixSource {
+		t.Errorf("expected the file to be unchanged by a diff; actual\n%s", src)
+	}
+	if len(unfixed.Findings()) != 1 || unfixed.Findings()[0].RuleID != "other" {
+		t.Errorf("expected the finding of other to be unfixed; actual %v", unfixed.Items())
+	}
+
+	if _, err := report.Fix(nil); err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(fixSource, `"A"`, `"B"`, 1)
+	if src, _ := ioutil.ReadFile(file); string(src) != expected {
+		t.Errorf("expected:\n%s\nactual:\n%s", expected, src)
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Output formats of the findings of a report.
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatSARIF      = "sarif"
+	FormatCheckstyle = "checkstyle"
+	FormatGitHub     = "github"
+)
+
+// Formats lists the output formats of the findings of a report.
+var Formats = []string{FormatText, FormatJSON, FormatSARIF, FormatCheckstyle, FormatGitHub}
+
+// Write writes the findings of the report to w in format, on behalf of the
+// linter named tool:
+//   - text: a line per finding, as returned by Items
+//   - json: an array of findings
+//   - sarif: a SARIF 2.1.0 log, for code scanning
+//   - checkstyle: a Checkstyle XML report
+//   - github: GitHub Actions workflow commands, which annotate the files
+func (lr *Report) Write(w io.Writer, format string, tool string) error {
+	switch format {
+	case FormatText:
+		for _, item := range lr.Items() {
+			if _, err := fmt.Fprintln(w, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatJSON:
+		return writeJSON(w, lr.findings)
+	case FormatSARIF:
+		return writeSARIF(w, lr.findings, tool)
+	case FormatCheckstyle:
+		return writeCheckstyle(w, lr.findings)
+	case FormatGitHub:
+		return writeGitHub(w, lr.findings)
+	default:
+		return fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+type jsonFinding struct {
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	RuleID   string   `json:"rule,omitempty"`
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity"`
+}
+
+func writeJSON(w io.Writer, findings []Finding) error {
+	jfs := make([]jsonFinding, 0, len(findings))
+	for _, f := range findings {
+		jfs = append(jfs, jsonFinding{
+			File:     f.Pos.Filename,
+			Line:     f.Pos.Line,
+			Column:   f.Pos.Column,
+			RuleID:   f.RuleID,
+			Message:  f.Message,
+			Severity: f.Severity,
+		})
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(jfs)
+}
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     Severity        `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func writeSARIF(w io.Writer, findings []Finding, tool string) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: tool, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	ids := map[string]bool{}
+	for _, f := range findings {
+		result := sarifResult{
+			RuleID:  f.RuleID,
+			Level:   f.Severity,
+			Message: sarifMessage{Text: f.Message},
+		}
+		if f.Pos.Filename != "" {
+			result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(relPath(f.Pos.Filename))},
+				Region:           sarifRegion{StartLine: f.Pos.Line, StartColumn: f.Pos.Column},
+			}}}
+		}
+		run.Results = append(run.Results, result)
+		if f.RuleID != "" {
+			ids[f.RuleID] = true
+		}
+	}
+	for id := range ids {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id})
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int      `xml:"line,attr,omitempty"`
+	Column   int      `xml:"column,attr,omitempty"`
+	Severity Severity `xml:"severity,attr"`
+	Message  string   `xml:"message,attr"`
+	Source   string   `xml:"source,attr,omitempty"`
+}
+
+func writeCheckstyle(w io.Writer, findings []Finding) error {
+	report := checkstyleReport{Version: "5.0"}
+	files := map[string]int{}
+	for _, f := range findings {
+		i, ok := files[f.Pos.Filename]
+		if !ok {
+			i = len(report.Files)
+			files[f.Pos.Filename] = i
+			report.Files = append(report.Files, checkstyleFile{Name: f.Pos.Filename})
+		}
+		report.Files[i].Errors = append(report.Files[i].Errors, checkstyleError{
+			Line:     f.Pos.Line,
+			Column:   f.Pos.Column,
+			Severity: f.Severity,
+			Message:  f.Message,
+			Source:   f.RuleID,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// githubEscaper escapes the data of workflow commands, properties also
+// escaping their separators.
+var (
+	githubEscaper         = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func writeGitHub(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		var properties []string
+		if f.Pos.Filename != "" {
+			properties = append(properties,
+				"file="+githubPropertyEscaper.Replace(filepath.ToSlash(relPath(f.Pos.Filename))),
+				fmt.Sprintf("line=%d", f.Pos.Line),
+				fmt.Sprintf("col=%d", f.Pos.Column))
+		}
+		if f.RuleID != "" {
+			properties = append(properties, "title="+githubPropertyEscaper.Replace(f.RuleID))
+		}
+		command := "::" + string(f.Severity)
+		if len(properties) > 0 {
+			command += " " + strings.Join(properties, ",")
+		}
+		if _, err := fmt.Fprintf(w, "%s::%s\n", command, githubEscaper.Replace(f.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relPath returns path relative to the working directory if it is in it,
+// as expected by code scanning and annotations, otherwise path.
+func relPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
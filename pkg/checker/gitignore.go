@@ -0,0 +1,134 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitignore holds the patterns of the .gitignore file of a directory, and the
+// gitignore of its parent directory, which patterns apply first.
+type gitignore struct {
+	dir      string
+	patterns []ignorePattern
+	parent   *gitignore
+}
+
+// ignorePattern is a pattern of a .gitignore file.
+type ignorePattern struct {
+	re *regexp.Regexp
+	// negate re-includes the paths matching the pattern, as !pattern does.
+	negate bool
+	// dirOnly matches directories only, as pattern/ does.
+	dirOnly bool
+	// anchored matches the path relative to the directory of the .gitignore
+	// file, rather than the base name, as the patterns with a / do.
+	anchored bool
+}
+
+// loadGitignore returns the gitignore of the directory dir, with the patterns
+// of the .gitignore files of its parent directories up to the root of its git
+// repository.
+func loadGitignore(dir string) *gitignore {
+	var parent *gitignore
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if up := filepath.Dir(dir); up != dir {
+			parent = loadGitignore(up)
+		}
+	}
+	return readGitignore(dir, parent)
+}
+
+// readGitignore returns the gitignore of the directory dir, given the gitignore
+// of its parent directory. It is parent if dir has no .gitignore file.
+func readGitignore(dir string, parent *gitignore) *gitignore {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return parent
+	}
+	defer f.Close()
+
+	gi := &gitignore{dir: dir, parent: parent}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p ignorePattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		// Escaped special characters are matched literally by the glob.
+		line = strings.NewReplacer(`\#`, "#", `\!`, "!", `\ `, " ").Replace(line)
+		re, err := globRegexp(line)
+		if err != nil {
+			continue
+		}
+		p.re = re
+		gi.patterns = append(gi.patterns, p)
+	}
+	return gi
+}
+
+// ignored returns true if the file at path, a directory if isDir is true, is
+// ignored by the .gitignore files. As git does, the last matching pattern
+// wins, and the patterns of a directory win over the ones of its parents.
+func (gi *gitignore) ignored(path string, isDir bool) bool {
+	if gi == nil {
+		return false
+	}
+	ignored := gi.parent.ignored(path, isDir)
+	rel, err := filepath.Rel(gi.dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ignored
+	}
+	rel = filepath.ToSlash(rel)
+	for _, p := range gi.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		name := rel
+		if !p.anchored {
+			name = filepath.Base(path)
+		}
+		if p.re.MatchString(name) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// skipDir returns true if the directory at path is skipped when walking the
+// files to check, as the go command does for vendor and testdata directories,
+// and the ones which names begin with . or _.
+func skipDir(path string) bool {
+	name := filepath.Base(path)
+	return name == "vendor" || name == "testdata" ||
+		strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
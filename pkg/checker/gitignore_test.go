@@ -0,0 +1,90 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitignore(t *testing.T) {
+	root, err := ioutil.TempDir("", "gitignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		".gitignore":     "# generated files\n*.gen.go\n!keep.gen.go\nbuild/\n/out\n\\#hash\n",
+		"pkg/.gitignore": "keep.gen.go\nlocal/*.go\n",
+	}
+	for _, dir := range []string{".git", "pkg/local"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"a.go", false, false},
+		{"a.gen.go", false, true},
+		{"pkg/a.gen.go", false, true},
+		{"keep.gen.go", false, false},
+		{"pkg/keep.gen.go", false, true},
+		{"build", true, true},
+		{"build", false, false},
+		{"pkg/build", true, true},
+		{"out", true, true},
+		{"pkg/out", true, false},
+		{"#hash", false, true},
+		{"pkg/local/a.go", false, true},
+		{"local/a.go", false, false},
+	}
+	gi := loadGitignore(filepath.Join(root, "pkg", "local"))
+	for _, test := range tests {
+		path := filepath.Join(root, test.path)
+		if actual := gi.ignored(path, test.isDir); actual != test.ignored {
+			t.Errorf("%s: expected ignored %v; actual %v", test.path, test.ignored, actual)
+		}
+	}
+}
+
+func TestSkipDir(t *testing.T) {
+	tests := []struct {
+		path    string
+		skipped bool
+	}{
+		{"pkg", false},
+		{"pkg/testdata", true},
+		{"vendor", true},
+		{"pkg/_output", true},
+		{"pkg/.cache", true},
+		{"pkg/data", false},
+	}
+	for _, test := range tests {
+		if actual := skipDir(test.path); actual != test.skipped {
+			t.Errorf("%s: expected skipped %v; actual %v", test.path, test.skipped, actual)
+		}
+	}
+}
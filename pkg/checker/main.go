@@ -0,0 +1,123 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Linter is a command line linter built on the checker, run by Main.
+type Linter struct {
+	// Name identifies the linter in its reports, e.g. "testlinter".
+	Name string
+
+	// DefaultConfigPath is the configuration file the linter reads, if it
+	// exists, when no --config is given.
+	DefaultConfigPath string
+
+	// Lint checks the files of args with the configuration file at configPath,
+	// empty if --config is not given, and returns the report of the lint
+	// errors.
+	Lint func(args []string, configPath string) (*Report, error)
+
+	// Run, if set, is called instead of Lint once the flags are parsed, for
+	// the modes selected by flags of the linter itself. Linting goes on if it
+	// returns false.
+	Run func(args []string, configPath string, format string) (bool, error)
+}
+
+// Main parses the flags common to the linters, lints the files given as
+// arguments with linter, applies or prints the suggested fixes, writes the
+// report and exits. The exit code is 2 if there are lint errors, unapplied
+// fixes or errors, and 0 otherwise.
+func Main(linter Linter) {
+	configPath := flag.String("config", "",
+		"path of the configuration file, "+linter.DefaultConfigPath+" if it exists")
+	format := flag.String("format", FormatText,
+		"output format of the lint errors, one of "+strings.Join(Formats, ", "))
+	flag.BoolVar(&ReportUnusedSuppressions, "report-unused-suppressions", false,
+		"report the lint:ignore directives which suppress no lint error")
+	flag.IntVar(&Workers, "workers", Workers, "number of files checked concurrently")
+	flag.StringVar(&ChangedSince, "changed-since", "",
+		"only check the files changed since the given git ref, e.g. origin/master")
+	flag.StringVar(&CacheDir, "cache-dir", "",
+		"directory of the cache of the lint errors of unchanged files, no cache if empty")
+	fix := flag.Bool("fix", false, "apply the suggested fixes of the lint errors")
+	diff := flag.Bool("diff", false,
+		"print the suggested fixes of the lint errors as unified diffs instead of applying them")
+	flag.Parse()
+
+	if *diff && *format != FormatText {
+		exit(fmt.Errorf("--diff only supports the text format"))
+	}
+
+	if linter.Run != nil {
+		done, err := linter.Run(flag.Args(), *configPath, *format)
+		if err != nil {
+			exit(err)
+		}
+		if done {
+			os.Exit(0)
+		}
+	}
+
+	exitCode := 0
+	report, err := linter.Lint(flag.Args(), *configPath)
+	if err != nil {
+		exit(err)
+	}
+	if *fix || *diff {
+		// Only the lint errors which are not fixed are reported.
+		var changes bytes.Buffer
+		var w io.Writer
+		if *diff {
+			w = &changes
+		}
+		if report, err = report.Fix(w); err != nil {
+			exit(err)
+		}
+		if changes.Len() > 0 {
+			exitCode = 2
+			if _, err := os.Stdout.Write(changes.Bytes()); err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+			}
+		}
+	}
+	if len(report.Findings()) > 0 {
+		exitCode = 2
+	}
+	// Lint errors are printed to stderr as text, and to stdout otherwise to
+	// be consumed by other tools.
+	out := os.Stdout
+	if *format == FormatText {
+		out = os.Stderr
+	}
+	if err := report.Write(out, *format, linter.Name); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		exitCode = 2
+	}
+	os.Exit(exitCode)
+}
+
+// exit prints err and exits with code 2.
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err.Error())
+	os.Exit(2)
+}
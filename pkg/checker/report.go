@@ -19,9 +19,45 @@ import (
 	"go/token"
 )
 
+// Severity is the severity of a lint error.
+type Severity string
+
+// Severities of lint errors.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a lint error found by a rule, or a failure to check a file, which
+// has a message only.
+type Finding struct {
+	Pos      token.Position
+	RuleID   string
+	Message  string
+	Severity Severity
+	// Fix is the suggested fix of the lint error, if any.
+	Fix *SuggestedFix
+}
+
+// String formats the finding as file:line:column:message (rule ID).
+func (f Finding) String() string {
+	if f.RuleID == "" {
+		return f.Message
+	}
+	return fmt.Sprintf("%v:%v:%v:%s (%s)",
+		f.Pos.Filename,
+		f.Pos.Line,
+		f.Pos.Column,
+		f.Message,
+		f.RuleID)
+}
+
 // Report populates lint report.
 type Report struct {
-	items []string
+	findings []Finding
+	// reportf, if set, receives the findings instead, e.g. to turn them into
+	// the diagnostics of an analysis pass.
+	reportf func(f Finding)
 }
 
 // NewLintReport creates and returns a Report object.
@@ -31,21 +67,49 @@ func NewLintReport() *Report {
 
 // Items returns formatted report as a string slice.
 func (lr *Report) Items() []string {
-	return lr.items
+	var items []string
+	for _, f := range lr.findings {
+		items = append(items, f.String())
+	}
+	return items
+}
+
+// Findings returns the findings of the report.
+func (lr *Report) Findings() []Finding {
+	return lr.findings
 }
 
 // AddItem creates a new lint error report.
 func (lr *Report) AddItem(pos token.Position, id string, msg string) {
-	item := fmt.Sprintf("%v:%v:%v:%s (%s)",
-		pos.Filename,
-		pos.Line,
-		pos.Column,
-		msg,
-		id)
-	lr.AddString(item)
+	lr.AddFinding(Finding{
+		Pos:      pos,
+		RuleID:   id,
+		Message:  msg,
+		Severity: SeverityError,
+	})
+}
+
+// AddFix creates a new lint error report, which fix fixes.
+func (lr *Report) AddFix(pos token.Position, id string, msg string, fix SuggestedFix) {
+	lr.AddFinding(Finding{
+		Pos:      pos,
+		RuleID:   id,
+		Message:  msg,
+		Severity: SeverityError,
+		Fix:      &fix,
+	})
 }
 
 // AddString creates a new string line in report.
 func (lr *Report) AddString(msg string) {
-	lr.items = append(lr.items, msg)
+	lr.AddFinding(Finding{Message: msg, Severity: SeverityError})
+}
+
+// AddFinding adds the finding f to the report.
+func (lr *Report) AddFinding(f Finding) {
+	if lr.reportf != nil {
+		lr.reportf(f)
+		return
+	}
+	lr.findings = append(lr.findings, f)
 }
@@ -17,6 +17,7 @@ package checker
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
 	"os"
 )
 
@@ -28,6 +29,17 @@ type Rule interface {
 	Check(aNode ast.Node, fs *token.FileSet, lrp *Report)
 }
 
+// TypedRule is a Rule which uses the type information of the package of the
+// checked file, e.g. to match calls by the functions they resolve to rather
+// than by name. CheckTyped is called instead of Check, with a nil info when
+// the package of the file could not be loaded.
+type TypedRule interface {
+	Rule
+	// CheckTyped verifies if aNode passes rule check, given the type
+	// information info. If verification fails lrp creates a report.
+	CheckTyped(aNode ast.Node, info *types.Info, fs *token.FileSet, lrp *Report)
+}
+
 // RulesFactory is interface to get Rules from a file path.
 type RulesFactory interface {
 	// GetRules returns a list of rules used to check against the files.
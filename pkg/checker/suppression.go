@@ -0,0 +1,189 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+var (
+	// ReportUnusedSuppressions reports the lint:ignore directives which
+	// suppress no lint error.
+	ReportUnusedSuppressions = false
+)
+
+const (
+	// ignoreDirective suppresses lint errors of rules on the next line, or in
+	// the declaration or statement on the next line, or on its own line when it
+	// follows code.
+	ignoreDirective = "lint:ignore"
+	// fileIgnoreDirective suppresses lint errors of rules in the whole file.
+	fileIgnoreDirective = "lint:file-ignore"
+	// suppressionID is the rule ID of lint errors about the directives.
+	suppressionID = "lint_ignore"
+)
+
+// suppression is a lint:ignore or lint:file-ignore directive of a file, e.g.
+// //lint:ignore no_sleep,no_goroutine waits for the server to start
+type suppression struct {
+	pos    token.Position // position of the directive
+	ids    []string       // IDs of the suppressed rules
+	reason string
+	// file is true if the lint errors are suppressed in the whole file,
+	// otherwise from the line from to the line to.
+	file     bool
+	from, to int
+	used     map[string]bool // IDs of the rules with suppressed lint errors
+}
+
+// parseSuppressions returns the suppressions of the comments of file.
+func parseSuppressions(file *ast.File, fs *token.FileSet) []*suppression {
+	var suppressions []*suppression
+	var lines *nodeLines
+	for _, cg := range file.Comments {
+		for _, c := range cg.List {
+			fields := strings.Fields(strings.TrimPrefix(c.Text, "//"))
+			if !strings.HasPrefix(c.Text, "//") || len(fields) < 2 ||
+				(fields[0] != ignoreDirective && fields[0] != fileIgnoreDirective) {
+				continue
+			}
+			s := &suppression{
+				pos:    fs.Position(c.Pos()),
+				ids:    strings.Split(fields[1], ","),
+				reason: strings.Join(fields[2:], " "),
+				file:   fields[0] == fileIgnoreDirective,
+				used:   map[string]bool{},
+			}
+			if !s.file {
+				if lines == nil {
+					lines = newNodeLines(file, fs)
+				}
+				s.from, s.to = lines.scope(s.pos.Line, c.Pos(), fs.Position(cg.End()).Line)
+			}
+			suppressions = append(suppressions, s)
+		}
+	}
+	return suppressions
+}
+
+// suppresses returns true if s suppresses the lint errors of the rule id on
+// line. Directives without a reason suppress nothing.
+func (s *suppression) suppresses(id string, line int) bool {
+	if s.reason == "" || (!s.file && (line < s.from || line > s.to)) {
+		return false
+	}
+	for _, sid := range s.ids {
+		if sid == id {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeLines indexes the nodes of a file by the line they start at.
+type nodeLines struct {
+	// ends maps each line to the last line of the outermost node starting at
+	// it.
+	ends map[int]int
+	// starts maps each line to the position of its first node.
+	starts map[int]token.Pos
+}
+
+func newNodeLines(file *ast.File, fs *token.FileSet) *nodeLines {
+	nl := &nodeLines{ends: map[int]int{}, starts: map[int]token.Pos{}}
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node.(type) {
+		case nil, *ast.File:
+			return true
+		case *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+		line := fs.Position(node.Pos()).Line
+		if end := fs.Position(node.End()).Line; end > nl.ends[line] {
+			nl.ends[line] = end
+		}
+		if start, ok := nl.starts[line]; !ok || node.Pos() < start {
+			nl.starts[line] = node.Pos()
+		}
+		return true
+	})
+	return nl
+}
+
+// scope returns the lines suppressed by a lint:ignore directive at pos on
+// line, in a comment group ending at groupEnd: the line of the directive if
+// it follows code, otherwise the outermost node on the line after the group.
+func (nl *nodeLines) scope(line int, pos token.Pos, groupEnd int) (int, int) {
+	if start, ok := nl.starts[line]; ok && start < pos {
+		return line, line
+	}
+	next := groupEnd + 1
+	if end, ok := nl.ends[next]; ok {
+		return next, end
+	}
+	return next, next
+}
+
+// suppressionReport returns a report which adds the lint errors of the
+// rules to report, unless they are suppressed.
+func suppressionReport(suppressions []*suppression, report *Report) *Report {
+	return &Report{reportf: func(f Finding) {
+		for _, s := range suppressions {
+			if s.suppresses(f.RuleID, f.Pos.Line) {
+				s.used[f.RuleID] = true
+				return
+			}
+		}
+		report.AddFinding(f)
+	}}
+}
+
+// reportSuppressions reports the directives suppressing the lint errors of
+// the rules ids without a reason and, if ReportUnusedSuppressions, those
+// which suppressed none. Directives for the rules of other linters are
+// ignored.
+func reportSuppressions(suppressions []*suppression, ids map[string]bool, report *Report) {
+	for _, s := range suppressions {
+		for _, id := range s.ids {
+			if !ids[id] {
+				continue
+			}
+			if s.reason == "" {
+				report.AddItem(s.pos, suppressionID, fmt.Sprintf(
+					"%s directive needs a reason: //%s <rule-id> <reason>", directiveOf(s), directiveOf(s)))
+				break
+			}
+			if ReportUnusedSuppressions && !s.used[id] {
+				report.AddFinding(Finding{
+					Pos:      s.pos,
+					RuleID:   suppressionID,
+					Message:  fmt.Sprintf("%s directive suppresses no lint error of %s", directiveOf(s), id),
+					Severity: SeverityWarning,
+				})
+			}
+		}
+	}
+}
+
+// directiveOf returns the name of the directive of s.
+func directiveOf(s *suppression) string {
+	if s.file {
+		return fileIgnoreDirective
+	}
+	return ignoreDirective
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+const suppressionSource = `package p
+
+//lint:file-ignore no_goroutine runs in the background
+
+func f() {
+	//lint:ignore no_sleep,no_os_env waits for the server
+	if true {
+		sleep()
+	}
+	sleep() //lint:ignore no_sleep waits once
+	//lint:ignore no_sleep
+	sleep()
+}
+`
+
+func TestParseSuppressions(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "p.go", suppressionSource, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suppressions := parseSuppressions(file, fs)
+
+	type scope struct {
+		line     int
+		ids      []string
+		reason   string
+		file     bool
+		from, to int
+	}
+	var actual []scope
+	for _, s := range suppressions {
+		actual = append(actual, scope{s.pos.Line, s.ids, s.reason, s.file, s.from, s.to})
+	}
+	expected := []scope{
+		{3, []string{"no_goroutine"}, "runs in the background", true, 0, 0},
+		{6, []string{"no_sleep", "no_os_env"}, "waits for the server", false, 7, 9},
+		{10, []string{"no_sleep"}, "waits once", false, 10, 10},
+		{11, []string{"no_sleep"}, "", false, 12, 12},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v; actual %+v", expected, actual)
+	}
+
+	tests := []struct {
+		id         string
+		line       int
+		suppressed bool
+	}{
+		{"no_goroutine", 1, true},
+		{"no_goroutine", 12, true},
+		{"no_sleep", 8, true},
+		{"no_os_env", 9, true},
+		{"no_sleep", 10, true},
+		{"no_os_env", 10, false},
+		{"no_sleep", 12, false},
+		{"no_sleep", 13, false},
+	}
+	for _, test := range tests {
+		suppressed := false
+		for _, s := range suppressions {
+			suppressed = suppressed || s.suppresses(test.id, test.line)
+		}
+		if suppressed != test.suppressed {
+			t.Errorf("%s on line %v: expected suppressed %v; actual %v",
+				test.id, test.line, test.suppressed, suppressed)
+		}
+	}
+}
+
+func TestReportSuppressions(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "p.go", suppressionSource, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suppressions := parseSuppressions(file, fs)
+
+	report := NewLintReport()
+	sr := suppressionReport(suppressions, report)
+	sr.AddItem(token.Position{Filename: "p.go", Line: 8}, "no_sleep", "sleep is disallowed")
+	sr.AddItem(token.Position{Filename: "p.go", Line: 12}, "no_sleep", "sleep is disallowed")
+
+	defer func(report bool) { ReportUnusedSuppressions = report }(ReportUnusedSuppressions)
+	ReportUnusedSuppressions = true
+	ids := map[string]bool{"no_sleep": true, "no_os_env": true}
+	reportSuppressions(suppressions, ids, report)
+
+	expected := []string{
+		"p.go:12:0:sleep is disallowed (no_sleep)",
+		"p.go:6:2:lint:ignore directive suppresses no lint error of no_os_env (lint_ignore)",
+		"p.go:10:10:lint:ignore directive suppresses no lint error of no_sleep (lint_ignore)",
+		"p.go:11:2:lint:ignore directive needs a reason: //lint:ignore <rule-id> <reason> (lint_ignore)",
+	}
+	if actual := report.Items(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v; actual %v", expected, actual)
+	}
+}
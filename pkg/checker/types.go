@@ -0,0 +1,249 @@
+// Copyright 2018 Istio Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typedFile is a file parsed and type checked as part of its package.
+type typedFile struct {
+	fileset *token.FileSet
+	file    *ast.File
+	info    *types.Info
+}
+
+// loadTypedFiles loads the packages in dirs, including their tests, and
+// returns their files with type information keyed by absolute path. Files of
+// packages which cannot be loaded or parsed are left out, to be checked
+// syntactically.
+//
+// Only the metadata of the packages is loaded by go/packages; the packages are
+// then type checked from source, dependencies without their function bodies,
+// as export data of recent compilers cannot be read.
+func loadTypedFiles(dirs []string) map[string]*typedFile {
+	files := map[string]*typedFile{}
+	if len(dirs) == 0 {
+		return files
+	}
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+			packages.NeedImports | packages.NeedDeps,
+		Tests: true,
+		// The packages are loaded in the module of the files rather than the
+		// one of the working directory.
+		Dir: dirs[0],
+	}
+	pkgs, err := packages.Load(cfg, dirs...)
+	if err != nil {
+		return files
+	}
+	l := &typeLoader{
+		fileset: token.NewFileSet(),
+		sizes:   types.SizesFor("gc", runtime.GOARCH),
+		deps:    map[string]*types.Package{},
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			continue
+		}
+		syntax, info, ok := l.check(pkg)
+		if !ok {
+			continue
+		}
+		for i, file := range syntax {
+			fpath := pkg.CompiledGoFiles[i]
+			if _, ok := files[fpath]; ok {
+				continue
+			}
+			files[fpath] = &typedFile{
+				fileset: l.fileset,
+				file:    file,
+				info:    info,
+			}
+		}
+	}
+	return files
+}
+
+// typeLoader type checks packages loaded by go/packages from source.
+type typeLoader struct {
+	fileset *token.FileSet
+	sizes   types.Sizes
+	// deps holds the packages type checked as dependencies, by ID.
+	deps map[string]*types.Package
+}
+
+// check parses and type checks pkg with its function bodies, returning its
+// files and their type information, or false if a file cannot be parsed.
+// Type errors are tolerated, leaving the offending expressions untyped.
+func (l *typeLoader) check(pkg *packages.Package) ([]*ast.File, *types.Info, bool) {
+	syntax, ok := l.parse(pkg)
+	if !ok {
+		return nil, nil, false
+	}
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Implicits:  map[ast.Node]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+		Scopes:     map[ast.Node]*types.Scope{},
+	}
+	tc := &types.Config{
+		Importer: l.importer(pkg),
+		Sizes:    l.sizes,
+		Error:    func(error) {},
+	}
+	_, _ = tc.Check(pkg.PkgPath, l.fileset, syntax, info)
+	return syntax, info, true
+}
+
+// dep returns pkg type checked without its function bodies, which is enough
+// to check the packages importing it.
+func (l *typeLoader) dep(pkg *packages.Package) *types.Package {
+	if tpkg, ok := l.deps[pkg.ID]; ok {
+		return tpkg
+	}
+	syntax, _ := l.parse(pkg)
+	tc := &types.Config{
+		Importer:         l.importer(pkg),
+		Sizes:            l.sizes,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error:            func(error) {},
+	}
+	tpkg, _ := tc.Check(pkg.PkgPath, l.fileset, syntax, nil)
+	l.deps[pkg.ID] = tpkg
+	return tpkg
+}
+
+// parse parses the compiled files of pkg, returning false if one of them
+// cannot be parsed.
+func (l *typeLoader) parse(pkg *packages.Package) ([]*ast.File, bool) {
+	syntax := make([]*ast.File, 0, len(pkg.CompiledGoFiles))
+	for _, fpath := range pkg.CompiledGoFiles {
+		file, err := parser.ParseFile(l.fileset, fpath, nil, parser.ParseComments)
+		if err != nil {
+			return syntax, false
+		}
+		syntax = append(syntax, file)
+	}
+	return syntax, true
+}
+
+// importer resolves the imports of pkg to its dependencies.
+func (l *typeLoader) importer(pkg *packages.Package) types.Importer {
+	return importerFunc(func(path string) (*types.Package, error) {
+		if path == "unsafe" {
+			return types.Unsafe, nil
+		}
+		ipkg, ok := pkg.Imports[path]
+		if !ok {
+			return nil, fmt.Errorf("package %s is not imported by %s", path, pkg.ID)
+		}
+		return l.dep(ipkg), nil
+	})
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// dirsOf returns the distinct directories of paths, in order.
+func dirsOf(paths []string) []string {
+	var dirs []string
+	seen := map[string]bool{}
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// CalledFunc returns the function or method called by ce, or nil if it is
+// unknown, e.g. without type information or when calling a func value.
+func CalledFunc(info *types.Info, ce *ast.CallExpr) *types.Func {
+	if info == nil {
+		return nil
+	}
+	var id *ast.Ident
+	switch fun := ce.Fun.(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	f, _ := info.Uses[id].(*types.Func)
+	return f
+}
+
+// MatchPkgFuncCall returns true if ce calls the function name of the package
+// with import path pkgPath, whichever name the package is imported as. If the
+// called name is not resolved, e.g. without type information, it falls back to
+// matching the call as written against the last element of pkgPath.
+func MatchPkgFuncCall(info *types.Info, ce *ast.CallExpr, pkgPath string, name string) bool {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	if info == nil || info.Uses[sel.Sel] == nil {
+		pkg, ok := sel.X.(*ast.Ident)
+		return ok && pkg.Name == path.Base(pkgPath) && sel.Sel.Name == name
+	}
+	f := CalledFunc(info, ce)
+	if f == nil || f.Pkg() == nil {
+		return false
+	}
+	// Methods have receivers, package-level functions do not.
+	if f.Type().(*types.Signature).Recv() != nil {
+		return false
+	}
+	return f.Pkg().Path() == pkgPath && f.Name() == name
+}
+
+// StringValue returns the value of the expression e if it is a constant
+// string. Without type information, e should be a string literal.
+func StringValue(info *types.Info, e ast.Expr) (string, bool) {
+	if info != nil {
+		if tv, ok := info.Types[e]; ok && tv.Value != nil {
+			if tv.Value.Kind() != constant.String {
+				return "", false
+			}
+			return constant.StringVal(tv.Value), true
+		}
+	}
+	if bl, ok := e.(*ast.BasicLit); ok && bl.Kind == token.STRING {
+		s, err := strconv.Unquote(bl.Value)
+		return s, err == nil
+	}
+	return "", false
+}